@@ -18,7 +18,6 @@ import (
 	"github.com/FAU-CDI/drincw/pathbuilder"
 	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
 	"github.com/ncruces/zenity"
-	"muzzammil.xyz/jsonc"
 )
 
 func main() {
@@ -26,16 +25,27 @@ func main() {
 	if !ok {
 		return
 	}
-	builder, ok := loadSelectors()
+	selectors, ok := loadSelectors()
 	if !ok {
 		return
 	}
 
 	odbcs := odbc.NewServer(pb)
-	if err := builder.Apply(&odbcs); err != nil {
+	if err := selectors.Builder.ApplyDialect(&odbcs, selectors.SQLDialect(nil)); err != nil {
 		zenity.Error(fmt.Sprintf("Unable to apply builder: %s", err))
 	}
 
+	conn := selectors.Connection(flagConnection)
+	if flagResolveEnv {
+		var err error
+		conn, err = conn.Expand(os.LookupEnv)
+		if err != nil {
+			zenity.Error(fmt.Sprintf("Unable to resolve connection settings: %s", err))
+			return
+		}
+	}
+	conn.Apply(&odbcs)
+
 	doOdbc, doSelectors, doCancel := whatToDo()
 	switch {
 	case doCancel:
@@ -43,7 +53,7 @@ func main() {
 	case doOdbc:
 		ok = saveODBC(odbcs)
 	case doSelectors:
-		ok = saveSelectors(builder)
+		ok = saveSelectors(selectors)
 	}
 
 	if !ok {
@@ -92,10 +102,10 @@ func loadPathbuilder() (pb pathbuilder.Pathbuilder, ok bool) {
 const LOAD_SELECTORS = "Do you want to load a selectors file?"
 const SELECTOR_LOAD_FILE = "Which selector file do you want to load?"
 
-func loadSelectors() (builder sql.Builder, ob bool) {
+func loadSelectors() (selectors sql.Selectors, ob bool) {
 	err := zenity.Question(LOAD_SELECTORS, zenity.OKLabel("Yes"), zenity.CancelLabel("No"))
 	if err == zenity.ErrCanceled {
-		return builder, true
+		return selectors, true
 	}
 	if err != nil {
 		log.Fatal(err)
@@ -103,7 +113,7 @@ func loadSelectors() (builder sql.Builder, ob bool) {
 
 	source, err := zenity.SelectFile(zenity.Title(SELECTOR_LOAD_FILE))
 	if err == zenity.ErrCanceled {
-		return builder, false
+		return selectors, false
 	}
 	if err != nil {
 		log.Fatal(err)
@@ -112,14 +122,15 @@ func loadSelectors() (builder sql.Builder, ob bool) {
 	bytes, err := os.ReadFile(source)
 	if err != nil {
 		zenity.Error(fmt.Sprintf("Unable to load selectors from %s: %s", source, err))
-		return builder, false
+		return selectors, false
 	}
-	if err := jsonc.Unmarshal(bytes, &builder); err != nil {
+	selectors, err = sql.LoadSelectors(bytes)
+	if err != nil {
 		zenity.Error(fmt.Sprintf("Unable to load selectors from %s: %s", source, err))
-		return builder, false
+		return selectors, false
 	}
 
-	return builder, true
+	return selectors, true
 }
 
 const DO_TITLE = "What do you want to do?"
@@ -170,7 +181,7 @@ func saveODBC(odbc odbc.Server) bool {
 
 const SAVE_SELECTORS = "Where to save selectors file?"
 
-func saveSelectors(builder sql.Builder) bool {
+func saveSelectors(selectors sql.Selectors) bool {
 	path, err := zenity.SelectFileSave(zenity.Filename("selectors.jsonc"), zenity.Title(SAVE_SELECTORS))
 	if err == zenity.ErrCanceled {
 		return false
@@ -181,7 +192,7 @@ func saveSelectors(builder sql.Builder) bool {
 
 	var buffer bytes.Buffer
 
-	bytes, err := json.MarshalIndent(&builder, "", "    ")
+	bytes, err := json.MarshalIndent(&selectors, "", "    ")
 	if err != nil {
 		zenity.Error(fmt.Sprintf("Unable to marshal builder: %s", err))
 		return false
@@ -197,6 +208,9 @@ func saveSelectors(builder sql.Builder) bool {
 	return true
 }
 
+var flagConnection odbc.Connection
+var flagResolveEnv bool
+
 func init() {
 	var legalFlag bool = false
 	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
@@ -207,5 +221,8 @@ func init() {
 		}
 	}()
 
+	flagConnection.RegisterFlags(flag.CommandLine)
+	flag.BoolVar(&flagResolveEnv, "resolve-env", flagResolveEnv, "resolve ${ENV} placeholders in connection settings using environment variables, instead of keeping them in the generated file")
+
 	flag.Parse()
 }
@@ -10,13 +10,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"strings"
 
 	"github.com/FAU-CDI/drincw"
 	"github.com/FAU-CDI/drincw/internal/sql"
 	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/odbc/check"
+	"github.com/FAU-CDI/drincw/odbc/migrate"
 	"github.com/FAU-CDI/drincw/pathbuilder"
 	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
-	"muzzammil.xyz/jsonc"
 )
 
 func main() {
@@ -31,36 +33,119 @@ func main() {
 		log.Fatalf("Unable to load Pathbuilder: %s", err)
 	}
 
-	var builder sql.Builder
+	var dialect *sql.Dialect
+	if flagDialect != "" {
+		dialect, err = sql.DialectByName(flagDialect)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if flagCheck != "" {
+		checkODBC(flagCheck, pb)
+		return
+	}
+
+	if flagMigrate != "" {
+		migrateODBC(flagMigrate, pb, dialect)
+		return
+	}
+
+	if flagReverse != "" {
+		selectors, unmapped := sql.Reverse(loadODBC(flagReverse), pb)
+		for _, u := range unmapped {
+			log.Printf("Unable to map %s", u)
+		}
+		writeSelectors(selectors)
+		return
+	}
+
+	var selectors sql.Selectors
 	if flagLoadSelectors != "" {
 		bytes, err := os.ReadFile(flagLoadSelectors)
 		if err != nil {
 			log.Fatalf("Unable to load Selectors: %s", err)
 		}
-		if err := jsonc.Unmarshal(bytes, &builder); err != nil {
+		selectors, err = sql.LoadSelectors(bytes)
+		if err != nil {
 			log.Fatalf("Unable to load Selectors: %s", err)
 		}
 	} else {
-		builder = sql.NewBuilder(pb)
+		selectors.Builder = sql.NewBuilder(pb)
+		selectors.Dialect = dialect
 	}
 
+	dialect = selectors.SQLDialect(dialect)
+
 	odbcs := odbc.NewServer(pb)
-	if err := builder.Apply(&odbcs); err != nil {
+	if err := selectors.Builder.ApplyDialect(&odbcs, dialect); err != nil {
 		log.Fatalf("Unable to apply builder: %s", err)
 	}
 
+	conn := selectors.Connection(flagConnection)
+	if flagResolveEnv {
+		conn, err = conn.Expand(os.LookupEnv)
+		if err != nil {
+			log.Fatalf("Unable to resolve connection settings: %s", err)
+		}
+	}
+	conn.Apply(&odbcs)
+
 	switch {
 	case flagDumpSQL != "":
-		writeSQL(flagDumpSQL, pb, odbcs)
+		writeSQL(flagDumpSQL, pb, odbcs, dialect)
 	case flagDumpSelectors:
-		writeSelectors(builder)
+		writeSelectors(selectors)
 	default:
 		writeXML(odbcs)
 	}
 }
 
-func writeSelectors(builder sql.Builder) {
-	bytes, err := json.MarshalIndent(&builder, "", "    ")
+func loadODBC(path string) (server odbc.Server) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Unable to load ODBC: %s", err)
+	}
+	if err := xml.Unmarshal(bytes, &server); err != nil {
+		log.Fatalf("Unable to load ODBC: %s", err)
+	}
+	return server
+}
+
+func checkODBC(path string, pb pathbuilder.Pathbuilder) {
+	report := check.Check(loadODBC(path), pb)
+	if err := report.WriteText(os.Stdout); err != nil {
+		log.Fatalf("Unable to write report: %s", err)
+	}
+	if report.Errors() > 0 {
+		os.Exit(2)
+	}
+}
+
+func migrateODBC(path string, pb pathbuilder.Pathbuilder, dialect *sql.Dialect) {
+	var opts migrate.Options
+	opts.Remove = flagRemoveDeleted
+	opts.Dialect = dialect
+	if flagOldPathbuilder != "" {
+		old, err := pbxml.Load(flagOldPathbuilder)
+		if err != nil {
+			log.Fatalf("Unable to load old Pathbuilder: %s", err)
+		}
+		opts.Old = &old
+	}
+
+	server, changes, err := migrate.Migrate(loadODBC(path), pb, opts)
+	if err != nil {
+		log.Fatalf("Unable to migrate ODBC: %s", err)
+	}
+	for _, change := range changes {
+		log.Print(change)
+	}
+	writeXML(server)
+}
+
+func writeSelectors(selectors sql.Selectors) {
+	bytes, err := json.MarshalIndent(&selectors, "", "    ")
 	if err != nil {
 		log.Fatalf("Unable to Marshal Builder: %s", err)
 	}
@@ -76,16 +161,23 @@ func writeXML(odbc odbc.Server) {
 	fmt.Println(string(bytes))
 }
 
-func writeSQL(name string, pb pathbuilder.Pathbuilder, odbc odbc.Server) {
+func writeSQL(name string, pb pathbuilder.Pathbuilder, odbc odbc.Server, dialect *sql.Dialect) {
 	bundle := pb.Get(name)
 	if bundle == nil {
 		log.Fatalf("no such bundle: %s", name)
 	}
-	table := odbc.TableByID(bundle.Path.MachineName())
-	if table.Name == "" {
+
+	found := false
+	for _, table := range odbc.Tables {
+		if table.MainBundleID() != bundle.Path.Bundle {
+			continue
+		}
+		fmt.Println(dialect.ForTable(table))
+		found = true
+	}
+	if !found {
 		log.Fatalf("no table for: %s", flagDumpSQL)
 	}
-	fmt.Println(sql.ForTable(table))
 }
 
 var nArgs []string
@@ -93,6 +185,14 @@ var nArgs []string
 var flagLoadSelectors string
 var flagDumpSelectors bool
 var flagDumpSQL string
+var flagCheck string
+var flagReverse string
+var flagMigrate string
+var flagOldPathbuilder string
+var flagRemoveDeleted bool
+var flagConnection odbc.Connection
+var flagResolveEnv bool
+var flagDialect string
 
 func init() {
 	var legalFlag bool = false
@@ -107,6 +207,16 @@ func init() {
 	flag.StringVar(&flagLoadSelectors, "load-selectors", flagLoadSelectors, "load selector file")
 	flag.BoolVar(&flagDumpSelectors, "dump-selectors", flagDumpSelectors, "generate a selectors template to generate sql statements")
 	flag.StringVar(&flagDumpSQL, "sql", flagDumpSQL, "generate sql that the importer would run for the given bundle name")
+	flag.StringVar(&flagCheck, "check", flagCheck, "check the given odbc file against the pathbuilder instead of generating one, exit with code 2 if errors are found")
+	flag.StringVar(&flagReverse, "reverse", flagReverse, "reconstruct a selectors file from the sql in the given odbc file, reporting fields that can not be mapped")
+	flag.StringVar(&flagMigrate, "migrate", flagMigrate, "update the given odbc file to the pathbuilder, keeping its sql customizations")
+	flag.StringVar(&flagOldPathbuilder, "old-pathbuilder", flagOldPathbuilder, "pathbuilder the file passed to -migrate was created for, used to follow renamed and moved paths by uuid")
+	flag.BoolVar(&flagRemoveDeleted, "remove-deleted", flagRemoveDeleted, "with -migrate, remove tables, bundles and fields no longer in the pathbuilder instead of marking them with a comment")
+
+	flagConnection.RegisterFlags(flag.CommandLine)
+	flag.BoolVar(&flagResolveEnv, "resolve-env", flagResolveEnv, "resolve ${ENV} placeholders in connection settings using environment variables, instead of keeping them in the generated file")
+
+	flag.StringVar(&flagDialect, "dialect", flagDialect, "sql dialect to generate statements in, one of "+strings.Join(sql.DialectNames(), ", ")+" (defaults to the dialect of the selectors file, or mariadb)")
 
 	flag.Parse()
 	nArgs = flag.Args()
@@ -0,0 +1,158 @@
+package main
+
+// cSpell:words pathbuilder odbc
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"html/template"
+	"io"
+	"net/http"
+	"strings"
+
+	_ "embed"
+
+	"github.com/FAU-CDI/drincw/internal/assets"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/graph"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// maxExplorerUpload is the maximum size of a pathbuilder uploaded to the explorer
+const maxExplorerUpload = 10 << 20 // 10 MB
+
+// explorerPage is the context of the explorer page
+type explorerPage struct {
+	Error   string           // error to show to the user, if any
+	Bundles []explorerBundle // bundles of the uploaded pathbuilder, if any
+}
+
+// explorerBundle is a bundle shown in the explorer
+type explorerBundle struct {
+	explorerPath
+
+	Fields  []explorerPath
+	Bundles []explorerBundle
+
+	Graph template.HTML // svg image of this bundle and its child bundles
+}
+
+// explorerPath is a single bundle or field shown in the explorer
+type explorerPath struct {
+	pathbuilder.Path
+
+	Anchor        string   // id of the detail panel of this path
+	PathArray     []string // path array, with uris compacted
+	Datatype      string   // compacted datatype property, if any
+	DisambConcept string   // compacted concept at which this path is disambiguated, if any
+}
+
+// explorerHandler serves the explorer.
+//
+// A GET request shows the upload form.
+// A POST request renders the uploaded pathbuilder; the upload is discarded once the page has been rendered.
+func explorerHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html")
+	if r.Method != http.MethodPost {
+		explorerTemplate.Execute(w, explorerPage{})
+		return
+	}
+
+	page, err := newExplorerPage(w, r)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		explorerTemplate.Execute(w, explorerPage{Error: err.Error()})
+		return
+	}
+	explorerTemplate.Execute(w, page)
+}
+
+// newExplorerPage reads an uploaded pathbuilder from r and creates the explorer page for it
+func newExplorerPage(w http.ResponseWriter, r *http.Request) (page explorerPage, err error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxExplorerUpload)
+
+	file, _, err := r.FormFile("pathbuilder")
+	if err != nil {
+		return page, errExplorerNoUpload
+	}
+	defer file.Close()
+
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return page, errExplorerNoUpload
+	}
+
+	pb, err := pbxml.Unmarshal(content)
+	if err != nil {
+		return page, errExplorerPathbuilder
+	}
+
+	opts := graph.Options{
+		ColorBundle:   "red",
+		ColorDatatype: "blue",
+	}
+	if prefixes := strings.TrimSpace(r.FormValue("prefixes")); prefixes != "" {
+		if err := json.Unmarshal([]byte(prefixes), &opts.Prefixes); err != nil {
+			return page, errExplorerPrefixes
+		}
+	}
+
+	for _, bundle := range pb.Bundles() {
+		page.Bundles = append(page.Bundles, newExplorerBundle(bundle, opts))
+	}
+	return page, nil
+}
+
+// errors shown to the user of the explorer
+var (
+	errExplorerNoUpload    = errors.New("unable to read uploaded pathbuilder")
+	errExplorerPathbuilder = errors.New("unable to parse pathbuilder")
+	errExplorerPrefixes    = errors.New("unable to parse prefixes")
+)
+
+func newExplorerBundle(bundle *pathbuilder.Bundle, opts graph.Options) explorerBundle {
+	eb := explorerBundle{
+		explorerPath: newExplorerPath(bundle.Path, "bundle-"+bundle.MachineName(), opts),
+	}
+
+	for _, field := range bundle.Fields() {
+		eb.Fields = append(eb.Fields, newExplorerPath(field.Path, "field-"+bundle.MachineName()+"-"+field.MachineName(), opts))
+	}
+	for _, child := range bundle.Bundles() {
+		eb.Bundles = append(eb.Bundles, newExplorerBundle(child, opts))
+	}
+
+	// writing into a buffer can not fail
+	var buffer bytes.Buffer
+	graph.NewForBundles(opts, bundle).WriteSVG(&buffer)
+	eb.Graph = template.HTML(buffer.String())
+
+	return eb
+}
+
+func newExplorerPath(path pathbuilder.Path, anchor string, opts graph.Options) explorerPath {
+	ep := explorerPath{
+		Path:      path,
+		Anchor:    anchor,
+		PathArray: make([]string, len(path.PathArray)),
+	}
+	for i, uri := range path.PathArray {
+		ep.PathArray[i] = opts.FormatID(uri)
+	}
+	if datatype := path.Datatype(); datatype != "" {
+		ep.Datatype = opts.FormatID(datatype)
+	}
+	if index := 2 * (path.Disamb - 1); path.Disamb > 0 && index < len(path.PathArray) {
+		ep.DisambConcept = ep.PathArray[index]
+	}
+	return ep
+}
+
+//go:embed explorer.html
+var explorerHTML string
+
+var explorerTemplate = assets.Assetsexplorer.MustParseShared(
+	"explorer.html", explorerHTML,
+	template.FuncMap{},
+)
@@ -21,7 +21,6 @@ import (
 	"github.com/FAU-CDI/drincw/internal/sql"
 	"github.com/FAU-CDI/drincw/odbc"
 	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
-	"muzzammil.xyz/jsonc"
 )
 
 func main() {
@@ -39,6 +38,7 @@ func main() {
 		w.Write(robotsTXT)
 	})
 	http.Handle("/assets/", assets.AssetHandler)
+	http.HandleFunc("/explorer", explorerHandler)
 
 	http.HandleFunc("/api/v2/makeselectors", func(w http.ResponseWriter, r *http.Request) {
 		if isNotPost(w, r) {
@@ -92,19 +92,24 @@ func main() {
 		}
 
 		// create the odbc
-		builder := sql.NewBuilder(pb)
+		selectors := sql.Selectors{Builder: sql.NewBuilder(pb)}
 		if len(strings.TrimSpace(params[1])) > 0 {
-			if err := jsonc.Unmarshal([]byte(params[1]), &builder); isError(err, w, "unable to load selectors") {
+			selectors, err = sql.LoadSelectors([]byte(params[1]))
+			if isError(err, w, "unable to load selectors") {
 				return
 			}
 		}
 
 		odbcs := odbc.NewServer(pb)
-		err = builder.Apply(&odbcs)
+		err = selectors.Builder.ApplyDialect(&odbcs, selectors.SQLDialect(nil))
 		if isError(err, w, "") {
 			return
 		}
 
+		// the settings of the server are only defaults for those of the user.
+		// placeholders are never resolved, as they would expose the environment of the server
+		selectors.ConnectionDefaults(flagConnection).Apply(&odbcs)
+
 		// and marshal it!
 		w.Header().Set("Content-Type", "text/xml")
 		enc := xml.NewEncoder(w)
@@ -162,6 +167,7 @@ var indexTemplate = assets.Assetsodbc.MustMakeFunc(
 //
 
 var flagListen string = "localhost:8080"
+var flagConnection odbc.Connection
 
 func init() {
 	var legalFlag bool = false
@@ -174,6 +180,7 @@ func init() {
 	}()
 
 	flag.StringVar(&flagListen, "listen", flagListen, "address to listen on")
+	flagConnection.RegisterDefaultFlags(flag.CommandLine)
 
 	flag.Parse()
 }
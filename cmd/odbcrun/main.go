@@ -0,0 +1,197 @@
+// Command odbcrun executes an odbc file against a local sqlite database and prints the entities that would be imported
+package main
+
+// cSpell:words odbcrun odbc sqlite pathbuilder dryrun dbsql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FAU-CDI/drincw"
+	"github.com/FAU-CDI/drincw/internal/sql"
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/odbc/dryrun"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	if len(nArgs) != 1 {
+		log.Print("Usage: odbcrun [-help] [-db file.sqlite] [-csv file.csv,...] [-load-selectors selectors.json] [...flags] /path/to/odbc.xml")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	server := loadODBC(nArgs[0])
+	if flagLoadSelectors != "" {
+		regenerateSQL(&server, flagLoadSelectors)
+	}
+	if flagTable != "" {
+		tables := server.Tables[:0:0]
+		for _, table := range server.Tables {
+			if table.Name == flagTable {
+				tables = append(tables, table)
+			}
+		}
+		if len(tables) == 0 {
+			log.Fatalf("no such table: %s", flagTable)
+		}
+		server.Tables = tables
+	}
+
+	db, err := dbsql.Open("sqlite", flagDB)
+	if err != nil {
+		log.Fatalf("Unable to open database: %s", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // an in-memory database only exists within a single connection
+
+	for _, path := range splitList(flagCSV) {
+		loadCSV(ctx, db, path)
+	}
+
+	var opts dryrun.Options
+	if flagPathbuilder != "" {
+		pb, err := pbxml.Load(flagPathbuilder)
+		if err != nil {
+			log.Fatalf("Unable to load Pathbuilder: %s", err)
+		}
+		opts.Cardinality = dryrun.Cardinalities(pb)
+	}
+	opts.Required = make(map[string]bool)
+	for _, field := range splitList(flagRequired) {
+		opts.Required[field] = true
+	}
+
+	report, err := dryrun.Run(ctx, db, server, opts)
+	if err != nil {
+		log.Fatalf("Unable to run odbc: %s", err)
+	}
+
+	if flagJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(report)
+	} else {
+		err = report.WriteText(os.Stdout)
+	}
+	if err != nil {
+		log.Fatalf("Unable to write report: %s", err)
+	}
+
+	if report.Issues() > 0 {
+		os.Exit(2)
+	}
+}
+
+func loadODBC(path string) (server odbc.Server) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Unable to load ODBC: %s", err)
+	}
+	if err := xml.Unmarshal(bytes, &server); err != nil {
+		log.Fatalf("Unable to load ODBC: %s", err)
+	}
+	return server
+}
+
+// regenerateSQL replaces the sql statements of server by those generated from the selectors file at path.
+// Statements are generated for sqlite, regardless of the dialect of the selectors file.
+//
+// Each table is regenerated using the selectors of its main bundle with the same sql table name.
+// Tables without such selectors are kept as-is.
+func regenerateSQL(server *odbc.Server, path string) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Unable to load selectors: %s", err)
+	}
+	selectors, err := sql.LoadSelectors(bytes)
+	if err != nil {
+		log.Fatalf("Unable to load selectors: %s", err)
+	}
+
+	for i := range server.Tables {
+		table := &server.Tables[i]
+
+		found := false
+		for _, tb := range selectors.Builder[table.MainBundleID()] {
+			if tb.TableName != table.Name {
+				continue
+			}
+			if err := tb.ApplyDialect(table, sql.SQLite); err != nil {
+				log.Fatalf("Unable to generate sql for table %s: %s", table.Name, err)
+			}
+			found = true
+			break
+		}
+		if !found {
+			log.Printf("no selectors for table %s, keeping its sql", table.Name)
+		}
+	}
+}
+
+// loadCSV loads the csv file at path into a table named after the file
+func loadCSV(ctx context.Context, db *dbsql.DB, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Unable to load CSV: %s", err)
+	}
+	defer file.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := dryrun.LoadCSV(ctx, db, name, file); err != nil {
+		log.Fatalf("Unable to load CSV %s: %s", path, err)
+	}
+}
+
+func splitList(value string) (values []string) {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+var nArgs []string
+
+var flagDB = ":memory:"
+var flagCSV string
+var flagPathbuilder string
+var flagLoadSelectors string
+var flagRequired string
+var flagTable string
+var flagJSON bool
+
+func init() {
+	var legalFlag bool = false
+	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
+	defer func() {
+		if legalFlag {
+			fmt.Print(drincw.LegalText())
+			os.Exit(0)
+		}
+	}()
+
+	flag.StringVar(&flagDB, "db", flagDB, "sqlite database to run the odbc against")
+	flag.StringVar(&flagCSV, "csv", flagCSV, "comma-separated list of csv files to load into the database, each becomes a table named after the file")
+	flag.StringVar(&flagPathbuilder, "pathbuilder", flagPathbuilder, "pathbuilder to read field cardinalities from")
+	flag.StringVar(&flagLoadSelectors, "load-selectors", flagLoadSelectors, "regenerate the sql statements for sqlite from the given selectors file before running")
+	flag.StringVar(&flagRequired, "required", flagRequired, "comma-separated list of field ids that must have a value")
+	flag.StringVar(&flagTable, "table", flagTable, "only run the table with the given name")
+	flag.BoolVar(&flagJSON, "json", flagJSON, "write entities as json instead of a table")
+
+	flag.Parse()
+	nArgs = flag.Args()
+}
@@ -0,0 +1,157 @@
+// Command odbctriples converts the data selected by an odbc file into rdf, like the WissKI importer would
+package main
+
+// cSpell:words odbctriples odbc sqlite pathbuilder triplify dbsql ntriples wisski
+
+import (
+	"bufio"
+	"context"
+	dbsql "database/sql"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/FAU-CDI/drincw"
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/odbc/dryrun"
+	"github.com/FAU-CDI/drincw/odbc/triplify"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+
+	_ "modernc.org/sqlite"
+)
+
+func main() {
+	if len(nArgs) != 2 {
+		log.Print("Usage: odbctriples [-help] [-db file.sqlite] [-csv file.csv,...] [...flags] /path/to/pathbuilder.xml /path/to/odbc.xml")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+
+	pb, err := pbxml.Load(nArgs[0])
+	if err != nil {
+		log.Fatalf("Unable to load Pathbuilder: %s", err)
+	}
+	server := loadODBC(nArgs[1])
+
+	db, err := dbsql.Open("sqlite", flagDB)
+	if err != nil {
+		log.Fatalf("Unable to open database: %s", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // an in-memory database only exists within a single connection
+
+	for _, path := range strings.Split(flagCSV, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			loadCSV(ctx, db, path)
+		}
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	defer out.Flush()
+
+	var writer rdf.TripleWriter
+	var turtle *rdf.TurtleWriter
+	switch flagFormat {
+	case "ntriples":
+		writer = rdf.NewNTriplesWriter(out)
+	case "turtle":
+		if err := loadPrefixMap(prefixMap); err != nil {
+			log.Fatal(err)
+		}
+		turtle, err = rdf.NewTurtleWriter(out, prefixes)
+		if err != nil {
+			log.Fatalf("Unable to write triples: %s", err)
+		}
+		writer = turtle
+	default:
+		log.Fatalf("unknown format %q", flagFormat)
+	}
+
+	if err := triplify.Convert(ctx, db, server, pb, flagOptions, writer); err != nil {
+		log.Fatalf("Unable to convert: %s", err)
+	}
+	if turtle != nil {
+		if err := turtle.Close(); err != nil {
+			log.Fatalf("Unable to write triples: %s", err)
+		}
+	}
+}
+
+func loadODBC(path string) (server odbc.Server) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Unable to load ODBC: %s", err)
+	}
+	if err := xml.Unmarshal(bytes, &server); err != nil {
+		log.Fatalf("Unable to load ODBC: %s", err)
+	}
+	return server
+}
+
+// loadCSV loads the csv file at path into a table named after the file
+func loadCSV(ctx context.Context, db *dbsql.DB, path string) {
+	file, err := os.Open(path)
+	if err != nil {
+		log.Fatalf("Unable to load CSV: %s", err)
+	}
+	defer file.Close()
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	if err := dryrun.LoadCSV(ctx, db, name, file); err != nil {
+		log.Fatalf("Unable to load CSV %s: %s", path, err)
+	}
+}
+
+func loadPrefixMap(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return json.NewDecoder(file).Decode(&prefixes)
+}
+
+var nArgs []string
+
+var flagDB = ":memory:"
+var flagCSV string
+var flagFormat = "ntriples"
+var flagOptions triplify.Options
+var prefixMap string
+var prefixes = rdf.Prefixes{}
+
+func init() {
+	var legalFlag bool = false
+	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
+	defer func() {
+		if legalFlag {
+			fmt.Print(drincw.LegalText())
+			os.Exit(0)
+		}
+	}()
+
+	flag.StringVar(&flagDB, "db", flagDB, "sqlite database to read data from")
+	flag.StringVar(&flagCSV, "csv", flagCSV, "comma-separated list of csv files to load into the database, each becomes a table named after the file")
+	flag.StringVar(&flagFormat, "format", flagFormat, "output format, one of 'ntriples' or 'turtle'")
+	flag.StringVar(&prefixMap, "prefixes", "", "Load prefixes in json format from the given file (turtle only)")
+	flag.StringVar(&flagOptions.Base, "base", triplify.DefaultBase, "base uri for minted uris")
+	flag.StringVar(&flagOptions.Entity, "entity", triplify.DefaultEntity, "pattern for entity uris")
+	flag.StringVar(&flagOptions.Intermediate, "intermediate", triplify.DefaultIntermediate, "pattern for uris of intermediate nodes")
+	flag.StringVar(&flagOptions.Disambiguated, "disambiguated", triplify.DefaultDisambiguated, "pattern for uris of disambiguated nodes and referenced entities")
+
+	flag.Parse()
+	nArgs = flag.Args()
+}
@@ -0,0 +1,92 @@
+// Command pbcheck checks an rdf dump against the cardinalities and datatypes of a pathbuilder
+package main
+
+// cSpell:words pbcheck pathbuilder rdfcheck
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/FAU-CDI/drincw"
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+	"github.com/FAU-CDI/drincw/pathbuilder/rdfcheck"
+)
+
+func main() {
+	if len(nArgs) != 2 {
+		log.Print("Usage: pbcheck [-help] [...flags] /path/to/pathbuilder /path/to/dump.nt")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	pb, err := pbxml.Load(nArgs[0])
+	if err != nil {
+		log.Fatalf("Unable to load Pathbuilder: %s", err)
+	}
+
+	g, err := loadGraph(nArgs[1])
+	if err != nil {
+		log.Fatalf("Unable to load RDF dump: %s", err)
+	}
+
+	report := rdfcheck.Check(pb, g, opts)
+
+	if flagJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Unable to marshal report: %s", err)
+		}
+	} else {
+		if err := report.WriteText(os.Stdout); err != nil {
+			log.Fatalf("Unable to write report: %s", err)
+		}
+	}
+
+	if report.Count() > 0 {
+		os.Exit(2)
+	}
+}
+
+// loadGraph loads a graph from the given path, or standard input if path is "-"
+func loadGraph(path string) (*rdf.Graph, error) {
+	var r io.Reader
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		r = file
+	} else {
+		r = os.Stdin
+	}
+	return rdf.ReadGraph(r)
+}
+
+var nArgs []string
+
+var opts = rdfcheck.Options{Examples: 5}
+var flagJSON bool
+
+func init() {
+	var legalFlag bool = false
+	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
+	defer func() {
+		if legalFlag {
+			fmt.Print(drincw.LegalText())
+			os.Exit(0)
+		}
+	}()
+
+	flag.IntVar(&opts.Examples, "examples", opts.Examples, "maximum number of example entities to report per field and kind of issue")
+	flag.BoolVar(&flagJSON, "json", flagJSON, "write report as json instead of text")
+
+	flag.Parse()
+	nArgs = flag.Args()
+}
@@ -0,0 +1,100 @@
+// Command pbcoverage reports how many entities have values for each field of a pathbuilder
+package main
+
+// cSpell:words pbcoverage pathbuilder sparql
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/FAU-CDI/drincw"
+	"github.com/FAU-CDI/drincw/internal/endpoint"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/coverage"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+func main() {
+	if len(nArgs) < 1 || flagEndpoint.URL == "" {
+		log.Print("Usage: pbcoverage [-help] -endpoint URL [...flags] /path/to/pathbuilder bundles...")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	pb, err := pbxml.Load(nArgs[0])
+	if err != nil {
+		log.Fatalf("Unable to load Pathbuilder: %s", err)
+	}
+
+	bundles := pb.Bundles()
+	if len(nArgs) > 1 {
+		bundles = make([]*pathbuilder.Bundle, 0, len(nArgs)-1)
+		for _, name := range nArgs[1:] {
+			bundle := pb.FindBundle(name)
+			if bundle == nil {
+				log.Fatalf("no such bundle: %s", name)
+			}
+			bundles = append(bundles, bundle)
+		}
+	}
+
+	report, err := coverage.Measure(context.Background(), flagEndpoint, bundles...)
+	if err != nil {
+		log.Fatalf("Unable to measure coverage: %s", err)
+	}
+
+	if flagHTML == "" && flagCSV == "" {
+		if err := report.WriteCSV(os.Stdout); err != nil {
+			log.Fatalf("Unable to write report: %s", err)
+		}
+		return
+	}
+	if flagHTML != "" {
+		writeFile(flagHTML, report.WriteHTML)
+	}
+	if flagCSV != "" {
+		writeFile(flagCSV, report.WriteCSV)
+	}
+}
+
+func writeFile(path string, write func(w io.Writer) error) {
+	file, err := os.Create(path)
+	if err != nil {
+		log.Fatalf("Unable to create %s: %s", path, err)
+	}
+	defer file.Close()
+
+	if err := write(file); err != nil {
+		log.Fatalf("Unable to write %s: %s", path, err)
+	}
+}
+
+var nArgs []string
+
+var flagEndpoint endpoint.Endpoint
+var flagHTML string
+var flagCSV string
+
+func init() {
+	var legalFlag bool = false
+	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
+	defer func() {
+		if legalFlag {
+			fmt.Print(drincw.LegalText())
+			os.Exit(0)
+		}
+	}()
+
+	flag.StringVar(&flagEndpoint.URL, "endpoint", flagEndpoint.URL, "url of the sparql endpoint to query")
+	flag.StringVar(&flagEndpoint.Username, "user", flagEndpoint.Username, "username for the sparql endpoint")
+	flag.StringVar(&flagEndpoint.Password, "password", flagEndpoint.Password, "password for the sparql endpoint")
+	flag.StringVar(&flagHTML, "html", flagHTML, "write html report to the given file")
+	flag.StringVar(&flagCSV, "csv", flagCSV, "write csv report to the given file (defaults to standard output if -html is not given)")
+
+	flag.Parse()
+	nArgs = flag.Args()
+}
@@ -1,7 +1,7 @@
 // Command pbdot turns a pathbuilder into a dot graph
 package main
 
-// cSpell:words pbdot pathbuilder
+// cSpell:words pbdot pathbuilder plantuml graphml gexf cytoscape
 
 import (
 	"encoding/json"
@@ -14,13 +14,14 @@ import (
 	"github.com/FAU-CDI/drincw"
 	"github.com/FAU-CDI/drincw/pathbuilder"
 	"github.com/FAU-CDI/drincw/pathbuilder/dot"
+	"github.com/FAU-CDI/drincw/pathbuilder/graph"
 	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
 	"golang.org/x/exp/maps"
 )
 
 func main() {
 	if len(nArgs) < 1 {
-		log.Print("Usage: pbdot [-help] [...flags] /path/to/pathbuilder bundles...")
+		log.Print("Usage: pbdot [-help] [...flags] [-diff /path/to/old/pathbuilder] /path/to/pathbuilder bundles...")
 		flag.PrintDefaults()
 		os.Exit(1)
 	}
@@ -43,8 +44,71 @@ func main() {
 		bundles = maps.Values(bm)
 	}
 
-	g := dot.NewDotForBundles(opts, bundles...)
-	g.Write(os.Stdout)
+	if themePath != "" {
+		if format != "dot" {
+			log.Fatalf("Themes are only supported for format %q", "dot")
+		}
+		theme, err = dot.LoadTheme(themePath)
+		if err != nil {
+			log.Fatalf("Unable to load theme: %s", err)
+		}
+	}
+
+	g := newGraph(bundles)
+	if diff != "" {
+		old, err := pbxml.Load(diff)
+		if err != nil {
+			log.Fatalf("Unable to load old Pathbuilder: %s", err)
+		}
+
+		oldBundles := old.Bundles()
+		if len(nArgs) > 1 {
+			oldBundles = make([]*pathbuilder.Bundle, 0, len(nArgs)-1)
+			for _, b := range nArgs[1:] {
+				oldBundles = append(oldBundles, old.FindBundle(b))
+			}
+		}
+		g = graph.Diff(newGraph(oldBundles), g)
+	}
+	switch format {
+	case "dot":
+		err = writeDot(os.Stdout, g)
+	case "mermaid":
+		err = g.WriteMermaid(os.Stdout)
+	case "plantuml":
+		err = g.WritePlantUML(os.Stdout)
+	case "graphml":
+		err = g.WriteGraphML(os.Stdout)
+	case "gexf":
+		err = g.WriteGEXF(os.Stdout)
+	case "cytoscape":
+		err = g.WriteCytoscape(os.Stdout)
+	case "svg":
+		err = g.WriteSVG(os.Stdout)
+	default:
+		log.Fatalf("Unknown format %q", format)
+	}
+	if err != nil {
+		log.Fatalf("Unable to write graph: %s", err)
+	}
+}
+
+// newGraph creates a new graph for the given bundles
+func newGraph(bundles []*pathbuilder.Bundle) *graph.Graph {
+	if classes {
+		return graph.NewClassGraphForBundles(opts, bundles...)
+	}
+	return graph.NewForBundles(opts, bundles...)
+}
+
+// writeDot renders g into w using the loaded theme
+func writeDot(w io.Writer, g *graph.Graph) error {
+	d, err := theme.Render(g)
+	if err != nil {
+		return err
+	}
+	d.Write(w)
+	return nil
 }
 
 func loadPrefixMap(path string) error {
@@ -69,7 +133,12 @@ func loadPrefixMap(path string) error {
 
 var nArgs []string
 var prefixMap string
+var format = "dot"
+var classes bool
+var diff string
 var opts dot.Options
+var themePath string
+var theme dot.Theme
 
 func init() {
 	var legalFlag bool = false
@@ -85,11 +154,19 @@ func init() {
 
 	flag.BoolVar(&opts.FlatChildBundles, "flat", false, "Skip sub-bundle structure entirely")
 	flag.BoolVar(&opts.IndependentChildBundles, "isolate-child-bundles", false, "Render each child bundle independently")
-	flag.BoolVar(&opts.CopyChildBundleNodes, "copy-child-bundle-nodes", false, "Copy nodes in child bundles (experimental)")
+	flag.Bool("copy-child-bundle-nodes", false, "Deprecated: has no effect, nodes are shared by path prefix")
 
 	flag.StringVar(&opts.ColorBundle, "color-heads", "red", "Color for bundle heads")
 	flag.StringVar(&opts.ColorDatatype, "color-data", "blue", "Color for datatypes")
 
+	flag.BoolVar(&classes, "classes", classes, "Aggregate all paths into a single class-level graph, labeling edges with the number of fields using them")
+
+	flag.StringVar(&diff, "diff", diff, "Render the difference to the given older pathbuilder: removed parts are red and dashed, added parts green and unchanged parts grey")
+
+	flag.StringVar(&format, "format", format, "Output format: 'dot', 'mermaid', 'plantuml', 'graphml', 'gexf', 'cytoscape' or 'svg'")
+
+	flag.StringVar(&themePath, "theme", themePath, "Load a theme controlling layout, shapes, fonts, colors and labels of dot output from the given json file")
+
 	flag.StringVar(&prefixMap, "prefixes", "", "Load prefixes in json format from the given file")
 
 	flag.Parse()
@@ -0,0 +1,120 @@
+// Command pbentities reassembles sparql results into nested entities of a bundle
+package main
+
+// cSpell:words pbentities pathbuilder sparql
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/FAU-CDI/drincw"
+	"github.com/FAU-CDI/drincw/internal/endpoint"
+	"github.com/FAU-CDI/drincw/pathbuilder/entities"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+	"github.com/FAU-CDI/drincw/pathbuilder/sparql"
+)
+
+func main() {
+	if len(nArgs) < 2 || len(nArgs) > 3 {
+		log.Print("Usage: pbentities [-help] [...flags] /path/to/pathbuilder bundle [/path/to/results]")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	pb, err := pbxml.Load(nArgs[0])
+	if err != nil {
+		log.Fatalf("Unable to load Pathbuilder: %s", err)
+	}
+
+	bundle := pb.FindBundle(nArgs[1])
+	if bundle == nil {
+		log.Fatalf("no such bundle: %s", nArgs[1])
+	}
+
+	opts.Graph, err = sparql.ParseGraphMode(flagGraphMode)
+	if err != nil {
+		log.Fatal(err)
+	}
+	query := sparql.NewBundleQuery(bundle, opts)
+
+	if flagQuery {
+		fmt.Println(query)
+		return
+	}
+
+	var results endpoint.Results
+	switch {
+	case flagEndpoint.URL != "":
+		results, err = flagEndpoint.Select(context.Background(), query)
+		if err != nil {
+			log.Fatalf("Unable to query endpoint: %s", err)
+		}
+	case len(nArgs) == 3:
+		results, err = readResults(nArgs[2])
+		if err != nil {
+			log.Fatalf("Unable to read results: %s", err)
+		}
+	default:
+		results, err = endpoint.ParseResults(os.Stdin, "")
+		if err != nil {
+			log.Fatalf("Unable to read results: %s", err)
+		}
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(entities.Assemble(results, bundle)); err != nil {
+		log.Fatalf("Unable to marshal entities: %s", err)
+	}
+}
+
+func readResults(path string) (endpoint.Results, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return endpoint.Results{}, err
+		}
+		defer file.Close()
+		r = file
+	}
+	return endpoint.ParseResults(r, "")
+}
+
+var nArgs []string
+
+var opts sparql.Options
+var flagGraphMode = "variable"
+var flagQuery bool
+var flagEndpoint endpoint.Endpoint
+
+func init() {
+	var legalFlag bool = false
+	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
+	defer func() {
+		if legalFlag {
+			fmt.Print(drincw.LegalText())
+			os.Exit(0)
+		}
+	}()
+
+	flag.BoolVar(&flagQuery, "query", flagQuery, "print the sparql query for the bundle and exit")
+
+	flag.StringVar(&flagGraphMode, "graph-mode", flagGraphMode, "graphs to query: 'variable' (any named graph), 'default' (default graph), 'named' (the graph given by -graph) or 'from' (use -graph as default graph)")
+	flag.StringVar(&opts.GraphIRI, "graph", opts.GraphIRI, "iri of the graph to query (for -graph-mode 'named' or 'from')")
+	flag.IntVar(&opts.Limit, "limit", opts.Limit, "maximal number of entities to return (if positive)")
+	flag.IntVar(&opts.Offset, "offset", opts.Offset, "number of entities to skip (if positive)")
+	flag.StringVar(&opts.Entity, "entity", opts.Entity, "only return the entity with the given uri")
+
+	flag.StringVar(&flagEndpoint.URL, "endpoint", flagEndpoint.URL, "url of a sparql endpoint to send the query to, instead of reading results from a file")
+	flag.StringVar(&flagEndpoint.Username, "user", flagEndpoint.Username, "username for the sparql endpoint")
+	flag.StringVar(&flagEndpoint.Password, "password", flagEndpoint.Password, "password for the sparql endpoint")
+
+	flag.Parse()
+	nArgs = flag.Args()
+}
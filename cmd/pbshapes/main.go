@@ -0,0 +1,124 @@
+// Command pbshapes generates shapes to validate rdf data from a pathbuilder
+package main
+
+// cSpell:words pbshapes pathbuilder shacl shex shexc shexj
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"github.com/FAU-CDI/drincw"
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+	"github.com/FAU-CDI/drincw/pathbuilder/shacl"
+	"github.com/FAU-CDI/drincw/pathbuilder/shex"
+)
+
+func main() {
+	if len(nArgs) < 1 {
+		log.Print("Usage: pbshapes [-help] [...flags] /path/to/pathbuilder bundles...")
+		flag.PrintDefaults()
+		os.Exit(1)
+	}
+
+	if err := loadPrefixMap(prefixMap); err != nil {
+		log.Fatal(err)
+	}
+
+	pb, err := pbxml.Load(nArgs[0])
+	if err != nil {
+		log.Fatalf("Unable to load Pathbuilder: %s", err)
+	}
+
+	bundles := pb.Bundles()
+	if len(nArgs) > 1 {
+		bundles = make([]*pathbuilder.Bundle, 0, len(nArgs)-1)
+		for _, name := range nArgs[1:] {
+			bundle := pb.FindBundle(name)
+			if bundle == nil {
+				log.Fatalf("no such bundle: %s", name)
+			}
+			bundles = append(bundles, bundle)
+		}
+	}
+
+	switch flagFormat {
+	case "shacl":
+		err = shacl.WriteBundles(os.Stdout, shacl.Options{Prefixes: prefixes, Base: flagBase}, bundles...)
+	case "shexc":
+		schema := shex.NewForBundles(shex.Options{Base: flagBase}, bundles...)
+		err = schema.WriteShExC(os.Stdout, shexPrefixes())
+	case "shexj":
+		schema := shex.NewForBundles(shex.Options{Base: flagBase}, bundles...)
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "    ")
+		err = enc.Encode(schema)
+	default:
+		log.Fatalf("unknown format %q", flagFormat)
+	}
+	if err != nil {
+		log.Fatalf("Unable to write shapes: %s", err)
+	}
+}
+
+// shexPrefixes returns the prefixes to use for ShExC output
+func shexPrefixes() map[string]string {
+	all := map[string]string{
+		"xsd":   rdf.XSDNamespace,
+		"shape": flagBase,
+	}
+	for name, ns := range prefixes {
+		all[name] = ns
+	}
+	return all
+}
+
+func loadPrefixMap(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	var f io.Reader
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		f = file
+	} else {
+		f = os.Stdin
+	}
+
+	return json.NewDecoder(f).Decode(&prefixes)
+}
+
+var nArgs []string
+
+var flagFormat = "shacl"
+var flagBase string
+var prefixMap string
+var prefixes map[string]string
+
+func init() {
+	var legalFlag bool = false
+	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
+	defer func() {
+		if legalFlag {
+			fmt.Print(drincw.LegalText())
+			os.Exit(0)
+		}
+	}()
+
+	flag.StringVar(&flagFormat, "format", flagFormat, "format of shapes to generate, one of 'shacl', 'shexc' or 'shexj'")
+	flag.StringVar(&flagBase, "base", shacl.DefaultBase, "base iri for generated shapes")
+	flag.StringVar(&prefixMap, "prefixes", "", "Load prefixes in json format from the given file")
+
+	flag.Parse()
+	nArgs = flag.Args()
+}
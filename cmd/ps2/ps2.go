@@ -4,6 +4,7 @@ package main
 // cspell:words sparql
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -13,6 +14,7 @@ import (
 	"github.com/FAU-CDI/drincw"
 	"github.com/FAU-CDI/drincw/pathbuilder"
 	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+	"github.com/FAU-CDI/drincw/pathbuilder/sparql"
 )
 
 func main() {
@@ -22,6 +24,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if err := parseOptions(); err != nil {
+		log.Fatal(err)
+	}
+
 	pb, err := pbxml.Load(nArgs[0])
 	if err != nil {
 		log.Fatalf("Unable to load Pathbuilder: %s", err)
@@ -38,36 +44,44 @@ func main() {
 		log.Fatalf("Unable to load field")
 	}
 
-	var builder strings.Builder
-	var counter int
+	query := sparql.NewQuery(*field, opts)
 
-	var last, current string
-	current = "?v0"
-	for i, path := range field.PathArray {
-		counter++
-		if i%2 == 0 {
-			fmt.Fprintf(&builder, "        %s a <%s> .\n", current, path)
-			continue
-		}
+	if !flagJSON {
+		fmt.Println(query.SPARQL)
+		return
+	}
 
-		last, current = current, fmt.Sprintf("?v%d", counter)
-		fmt.Fprintf(&builder, "        %s <%s> %s .\n", last, path, current)
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "    ")
+	if err := enc.Encode(query); err != nil {
+		log.Fatalf("Unable to marshal query: %s", err)
 	}
+}
 
-	if field.DatatypeProperty != "" {
-		last, current = current, fmt.Sprintf("?v%d", counter)
-		fmt.Fprintf(&builder, "        %s <%s> %s .\n", last, field.DatatypeProperty, current)
+// parseOptions parses options that can not be set directly by flags
+func parseOptions() error {
+	var err error
+	opts.Graph, err = sparql.ParseGraphMode(flagGraphMode)
+	if err != nil {
+		return err
+	}
+	if (opts.Graph == sparql.GraphNamed || opts.Graph == sparql.GraphFrom) && opts.GraphIRI == "" {
+		return fmt.Errorf("graph mode %q requires -graph", flagGraphMode)
 	}
 
-	fmt.Println("SELECT * WHERE {")
-	fmt.Println("    GRAPH ?g {")
-	fmt.Print(builder.String())
-	fmt.Println("    }")
-	fmt.Println("}")
+	if flagLanguages != "" {
+		opts.Languages = strings.Split(flagLanguages, ",")
+	}
+	return nil
 }
 
 var nArgs []string
 
+var opts sparql.Options
+var flagGraphMode = "variable"
+var flagLanguages string
+var flagJSON bool
+
 func init() {
 	var legalFlag bool = false
 	flag.BoolVar(&legalFlag, "legal", legalFlag, "Display legal notices and exit")
@@ -78,6 +92,20 @@ func init() {
 		}
 	}()
 
+	flag.StringVar(&flagGraphMode, "graph-mode", flagGraphMode, "graphs to query: 'variable' (any named graph), 'default' (default graph), 'named' (the graph given by -graph) or 'from' (use -graph as default graph)")
+	flag.StringVar(&opts.GraphIRI, "graph", opts.GraphIRI, "iri of the graph to query (for -graph-mode 'named' or 'from')")
+
+	flag.BoolVar(&opts.Distinct, "distinct", opts.Distinct, "select only distinct solutions")
+	flag.IntVar(&opts.Limit, "limit", opts.Limit, "maximal number of results to return (if positive)")
+	flag.IntVar(&opts.Offset, "offset", opts.Offset, "number of results to skip (if positive)")
+
+	flag.StringVar(&opts.Entity, "entity", opts.Entity, "only match the entity with the given uri")
+	flag.StringVar(&opts.Regex, "regex", opts.Regex, "only match values matching the given regular expression")
+	flag.StringVar(&opts.Flags, "regex-flags", opts.Flags, "flags for -regex, e.g. 'i' for case-insensitive matching")
+	flag.StringVar(&flagLanguages, "lang", flagLanguages, "comma-separated list of language ranges values must match, e.g. 'en,de'")
+
+	flag.BoolVar(&flagJSON, "json", flagJSON, "output json containing the query and a mapping from variables to path elements")
+
 	flag.Parse()
 	nArgs = flag.Args()
 }
@@ -24,7 +24,7 @@ type Assets struct {
 	Styles  string // <link> tags inserted by the asset
 }
 
-//go:generate node build.mjs odbc
+//go:generate node build.mjs odbc explorer
 
 // MustParse parses a new template from the given source
 // and calls [RegisterAssoc] on it.
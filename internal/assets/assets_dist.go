@@ -8,3 +8,9 @@ var Assetsodbc = Assets{
 	Scripts: `<script type="module" src="/assets/odbc.3eabe410.js"></script><script src="/assets/odbc.3e6682b2.js" nomodule defer></script><script type="module" src="/assets/odbc.0c619639.js"></script><script src="/assets/odbc.d6f46846.js" nomodule defer></script>`,
 	Styles:  `<link rel="stylesheet" href="/assets/odbc.a49e4321.css">`,	
 }
+
+// Assetsexplorer contains assets for the 'explorer' entrypoint.
+var Assetsexplorer = Assets{
+	Scripts: `<script type="module" src="/assets/explorer.b4b53836.js"></script><script type="module" src="/assets/explorer.e8f83569.js"></script>`,
+	Styles:  `<link rel="stylesheet" href="/assets/explorer.103adc8e.css">`,	
+}
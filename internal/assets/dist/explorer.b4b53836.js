@@ -0,0 +1 @@
+const e=void 0;if(e){let n=document.createElement("footer");n.innerHTML=e,document.body.append(n)}
\ No newline at end of file
@@ -0,0 +1 @@
+const e=document.getElementById("search");function t(e){const t=e.trim().toLowerCase();document.querySelectorAll(".tree > li").forEach(e=>n(e,t))}function n(e,t){const l=""===t||(e.dataset.search??"").toLowerCase().includes(t);let o=!1;const r=e.querySelector(":scope > details > ul");null!==r&&Array.from(r.children).forEach(e=>{n(e,l?"":t)&&(o=!0)});const c=e.querySelector(":scope > details");null!==c&&""!==t&&o&&(c.open=!0);const s=l||o;return e.hidden=!s,s}null!==e&&e.addEventListener("input",()=>t(e.value));
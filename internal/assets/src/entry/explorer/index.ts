@@ -0,0 +1,37 @@
+const search = document.getElementById("search") as HTMLInputElement | null;
+
+//
+// SEARCH
+//
+
+// filter hides all bundles and fields that do not match query.
+// Bundles containing a match are kept, and opened.
+function filter(query: string) {
+    const needle = query.trim().toLowerCase();
+    document.querySelectorAll<HTMLLIElement>(".tree > li").forEach(item => filterItem(item, needle));
+}
+
+function filterItem(item: HTMLLIElement, needle: string): boolean {
+    const own = needle === "" || (item.dataset.search ?? "").toLowerCase().includes(needle);
+
+    let child = false;
+    const list = item.querySelector(":scope > details > ul");
+    if (list !== null) {
+        Array.from(list.children).forEach(c => {
+            if (filterItem(c as HTMLLIElement, own ? "" : needle)) child = true;
+        });
+    }
+
+    const details = item.querySelector(":scope > details") as HTMLDetailsElement | null;
+    if (details !== null && needle !== "" && child) {
+        details.open = true;
+    }
+
+    const visible = own || child;
+    item.hidden = !visible;
+    return visible;
+}
+
+if (search !== null) {
+    search.addEventListener("input", () => filter(search.value));
+}
@@ -0,0 +1,70 @@
+// Package endpoint implements querying a sparql endpoint using the SPARQL 1.1 protocol.
+package endpoint
+
+// cspell:words sparql
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Querier can run sparql SELECT queries
+type Querier interface {
+	Select(ctx context.Context, query string) (Results, error)
+}
+
+// Endpoint is a remote sparql endpoint.
+// It implements Querier.
+type Endpoint struct {
+	URL string // url of the endpoint
+
+	// Username and Password are used for basic authentication, if non-empty.
+	Username string
+	Password string
+
+	Client *http.Client // client to use for requests; defaults to http.DefaultClient
+}
+
+var _ Querier = Endpoint{}
+
+// Select sends the given query to the endpoint and parses the results.
+//
+// Queries are sent via POST using url-encoded parameters.
+// Results are requested in SPARQL JSON format, with SPARQL XML as a fallback.
+func (e Endpoint) Select(ctx context.Context, query string) (Results, error) {
+	body := url.Values{"query": {query}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.URL, strings.NewReader(body))
+	if err != nil {
+		return Results{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", MIMEResultsJSON+", "+MIMEResultsXML+";q=0.9")
+	if e.Username != "" || e.Password != "" {
+		req.SetBasicAuth(e.Username, e.Password)
+	}
+
+	client := e.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return Results{}, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 1024))
+		return Results{}, fmt.Errorf("endpoint returned %s: %s", res.Status, strings.TrimSpace(string(message)))
+	}
+
+	typ, _, _ := mime.ParseMediaType(res.Header.Get("Content-Type"))
+	return ParseResults(res.Body, typ)
+}
@@ -0,0 +1,195 @@
+package endpoint
+
+// cspell:words sparql bnode
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/FAU-CDI/drincw/internal/rdf"
+)
+
+// Media types of sparql results
+const (
+	MIMEResultsJSON = "application/sparql-results+json"
+	MIMEResultsXML  = "application/sparql-results+xml"
+)
+
+// Results represents the results of a sparql SELECT query
+type Results struct {
+	Variables []string              // variables in the order they were declared
+	Bindings  []map[string]rdf.Term // one binding per solution; unbound variables are omitted
+}
+
+// Get returns the value of the given variable in the solution with the given index.
+func (r Results) Get(index int, variable string) (rdf.Term, bool) {
+	if index < 0 || index >= len(r.Bindings) {
+		return rdf.Term{}, false
+	}
+	term, ok := r.Bindings[index][variable]
+	return term, ok
+}
+
+var errUnsupportedType = errors.New("unsupported results format")
+
+// ParseResults parses sparql results of the given media type from r.
+// If the media type is empty, the format is guessed from the first non-whitespace character of the input.
+func ParseResults(r io.Reader, typ string) (Results, error) {
+	switch typ {
+	case MIMEResultsJSON, "application/json":
+		return ParseJSON(r)
+	case MIMEResultsXML, "application/xml", "text/xml":
+		return ParseXML(r)
+	case "":
+		br := bufio.NewReader(r)
+		if isXML(br) {
+			return ParseXML(br)
+		}
+		return ParseJSON(br)
+	}
+	return Results{}, fmt.Errorf("%w: %q", errUnsupportedType, typ)
+}
+
+// isXML checks if the first character in r, ignoring whitespace and a byte order mark, is '<'.
+// Skipped bytes are consumed from r.
+func isXML(r *bufio.Reader) bool {
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return false
+		}
+		switch b {
+		case ' ', '\t', '\r', '\n', 0xEF, 0xBB, 0xBF:
+			continue
+		}
+		r.UnreadByte()
+		return b == '<'
+	}
+}
+
+// resultsJSON represents the SPARQL 1.1 Query Results JSON Format
+type resultsJSON struct {
+	Head struct {
+		Vars []string `json:"vars"`
+	} `json:"head"`
+	Results struct {
+		Bindings []map[string]termJSON `json:"bindings"`
+	} `json:"results"`
+}
+
+type termJSON struct {
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	Datatype string `json:"datatype"`
+	Language string `json:"xml:lang"`
+}
+
+func (t termJSON) Term() (rdf.Term, error) {
+	switch t.Type {
+	case "uri":
+		return rdf.NewIRI(t.Value), nil
+	case "bnode":
+		return rdf.NewBlankNode(t.Value), nil
+	case "literal", "typed-literal":
+		term := rdf.NewLiteral(t.Value, t.Datatype)
+		term.Language = t.Language
+		if term.Datatype == rdf.XSDString {
+			term.Datatype = ""
+		}
+		return term, nil
+	}
+	return rdf.Term{}, fmt.Errorf("unknown term type %q", t.Type)
+}
+
+// ParseJSON parses results in SPARQL JSON format
+func ParseJSON(r io.Reader) (results Results, err error) {
+	var data resultsJSON
+	if err := json.NewDecoder(r).Decode(&data); err != nil {
+		return results, err
+	}
+
+	results.Variables = data.Head.Vars
+	results.Bindings = make([]map[string]rdf.Term, len(data.Results.Bindings))
+	for i, binding := range data.Results.Bindings {
+		results.Bindings[i] = make(map[string]rdf.Term, len(binding))
+		for name, value := range binding {
+			results.Bindings[i][name], err = value.Term()
+			if err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
+
+// resultsXML represents the SPARQL Query Results XML Format
+type resultsXML struct {
+	Head struct {
+		Variables []struct {
+			Name string `xml:"name,attr"`
+		} `xml:"variable"`
+	} `xml:"head"`
+	Results struct {
+		Results []struct {
+			Bindings []bindingXML `xml:"binding"`
+		} `xml:"result"`
+	} `xml:"results"`
+}
+
+type bindingXML struct {
+	Name  string  `xml:"name,attr"`
+	URI   *string `xml:"uri"`
+	BNode *string `xml:"bnode"`
+
+	Literal *struct {
+		Value    string `xml:",chardata"`
+		Datatype string `xml:"datatype,attr"`
+		Language string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	} `xml:"literal"`
+}
+
+func (b bindingXML) Term() (rdf.Term, error) {
+	switch {
+	case b.URI != nil:
+		return rdf.NewIRI(*b.URI), nil
+	case b.BNode != nil:
+		return rdf.NewBlankNode(*b.BNode), nil
+	case b.Literal != nil:
+		return termJSON{
+			Type:     "literal",
+			Value:    b.Literal.Value,
+			Datatype: b.Literal.Datatype,
+			Language: b.Literal.Language,
+		}.Term()
+	}
+	return rdf.Term{}, fmt.Errorf("binding %q has no value", b.Name)
+}
+
+// ParseXML parses results in SPARQL XML format
+func ParseXML(r io.Reader) (results Results, err error) {
+	var data resultsXML
+	if err := xml.NewDecoder(r).Decode(&data); err != nil {
+		return results, err
+	}
+
+	results.Variables = make([]string, len(data.Head.Variables))
+	for i, variable := range data.Head.Variables {
+		results.Variables[i] = variable.Name
+	}
+
+	results.Bindings = make([]map[string]rdf.Term, len(data.Results.Results))
+	for i, result := range data.Results.Results {
+		results.Bindings[i] = make(map[string]rdf.Term, len(result.Bindings))
+		for _, binding := range result.Bindings {
+			results.Bindings[i][binding.Name], err = binding.Term()
+			if err != nil {
+				return results, err
+			}
+		}
+	}
+	return results, nil
+}
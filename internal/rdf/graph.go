@@ -0,0 +1,76 @@
+package rdf
+
+import "io"
+
+// Graph is a simple in-memory index of triples.
+//
+// It keeps the insertion order of subjects and objects, making traversal deterministic.
+type Graph struct {
+	outgoing  map[Term]map[string][]Term // subject => predicate => objects
+	instances map[string][]Term          // class => subjects with rdf:type class
+	seen      map[Triple]struct{}        // de-duplicates triples
+}
+
+// NewGraph creates a new empty graph
+func NewGraph() *Graph {
+	return &Graph{
+		outgoing:  make(map[Term]map[string][]Term),
+		instances: make(map[string][]Term),
+		seen:      make(map[Triple]struct{}),
+	}
+}
+
+// ReadGraph reads a new graph from the given N-Triples or N-Quads source
+func ReadGraph(r io.Reader) (*Graph, error) {
+	g := NewGraph()
+	err := ReadAll(r, func(t Triple) error {
+		g.Add(t)
+		return nil
+	})
+	return g, err
+}
+
+// Add adds a triple to this graph.
+// Duplicate triples are ignored.
+func (g *Graph) Add(t Triple) {
+	if _, ok := g.seen[t]; ok {
+		return
+	}
+	g.seen[t] = struct{}{}
+
+	predicates, ok := g.outgoing[t.Subject]
+	if !ok {
+		predicates = make(map[string][]Term)
+		g.outgoing[t.Subject] = predicates
+	}
+	predicates[t.Predicate.Value] = append(predicates[t.Predicate.Value], t.Object)
+
+	if t.Predicate.Value == Type && t.Object.Kind == IRI {
+		g.instances[t.Object.Value] = append(g.instances[t.Object.Value], t.Subject)
+	}
+}
+
+// Len returns the number of triples in this graph
+func (g *Graph) Len() int {
+	return len(g.seen)
+}
+
+// Instances returns all subjects with the given rdf:type
+func (g *Graph) Instances(class string) []Term {
+	return g.instances[class]
+}
+
+// Objects returns all objects of triples with the given subject and predicate
+func (g *Graph) Objects(subject Term, predicate string) []Term {
+	return g.outgoing[subject][predicate]
+}
+
+// HasType checks if subject has the given rdf:type
+func (g *Graph) HasType(subject Term, class string) bool {
+	for _, object := range g.Objects(subject, Type) {
+		if object.Kind == IRI && object.Value == class {
+			return true
+		}
+	}
+	return false
+}
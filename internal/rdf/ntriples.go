@@ -0,0 +1,259 @@
+package rdf
+
+// cspell:words ntriples nquads
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Reader reads triples from an N-Triples or N-Quads document.
+//
+// Graph labels of quads are ignored; every statement is returned as a triple.
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader creates a new reader reading from r
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	return &Reader{scanner: scanner}
+}
+
+// Read reads the next triple from the underlying reader.
+// When no more triples are available, returns io.EOF.
+func (r *Reader) Read() (Triple, error) {
+	for r.scanner.Scan() {
+		r.line++
+
+		line := strings.TrimSpace(r.scanner.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+
+		triple, err := parseStatement(line)
+		if err != nil {
+			return Triple{}, fmt.Errorf("line %d: %w", r.line, err)
+		}
+		return triple, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return Triple{}, err
+	}
+	return Triple{}, io.EOF
+}
+
+// ReadAll reads all triples from r and calls f for each of them.
+func ReadAll(r io.Reader, f func(Triple) error) error {
+	reader := NewReader(r)
+	for {
+		triple, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := f(triple); err != nil {
+			return err
+		}
+	}
+}
+
+// parseStatement parses a single N-Triples or N-Quads statement
+func parseStatement(line string) (t Triple, err error) {
+	rest := line
+
+	if t.Subject, rest, err = parseTerm(rest); err != nil {
+		return t, err
+	}
+	if !t.Subject.IsResource() {
+		return t, errInvalidSubject
+	}
+
+	if t.Predicate, rest, err = parseTerm(rest); err != nil {
+		return t, err
+	}
+	if t.Predicate.Kind != IRI {
+		return t, errInvalidPredicate
+	}
+
+	if t.Object, rest, err = parseTerm(rest); err != nil {
+		return t, err
+	}
+
+	// optional graph label
+	rest = strings.TrimLeft(rest, " \t")
+	if rest != "" && rest[0] != '.' {
+		if _, rest, err = parseTerm(rest); err != nil {
+			return t, err
+		}
+		rest = strings.TrimLeft(rest, " \t")
+	}
+
+	if rest == "" || rest[0] != '.' {
+		return t, errMissingDot
+	}
+
+	rest = strings.TrimSpace(rest[1:])
+	if rest != "" && rest[0] != '#' {
+		return t, errTrailingContent
+	}
+	return t, nil
+}
+
+var (
+	errInvalidSubject   = errors.New("subject must be an iri or blank node")
+	errInvalidPredicate = errors.New("predicate must be an iri")
+	errMissingDot       = errors.New("statement must end with '.'")
+	errTrailingContent  = errors.New("unexpected content after '.'")
+	errUnexpectedEnd    = errors.New("unexpected end of statement")
+	errUnclosedIRI      = errors.New("unclosed iri")
+	errUnclosedLiteral  = errors.New("unclosed literal")
+)
+
+// parseTerm parses a single term from the beginning of value
+func parseTerm(value string) (term Term, rest string, err error) {
+	value = strings.TrimLeft(value, " \t")
+	if value == "" {
+		return term, "", errUnexpectedEnd
+	}
+
+	switch {
+	case value[0] == '<':
+		iri, rest, err := parseIRI(value)
+		return NewIRI(iri), rest, err
+	case strings.HasPrefix(value, "_:"):
+		end := strings.IndexAny(value, " \t")
+		if end == -1 {
+			end = len(value)
+		}
+		// a blank node label may be directly followed by the terminating '.'
+		label := strings.TrimSuffix(value[2:end], ".")
+		return NewBlankNode(label), value[2+len(label):], nil
+	case value[0] == '"':
+		return parseLiteral(value)
+	}
+	return term, "", fmt.Errorf("unexpected character %q", value[0])
+}
+
+// parseIRI parses an iri enclosed in '<' and '>'
+func parseIRI(value string) (iri string, rest string, err error) {
+	end := strings.IndexRune(value, '>')
+	if end == -1 {
+		return "", "", errUnclosedIRI
+	}
+	iri, err = unescape(value[1:end])
+	return iri, value[end+1:], err
+}
+
+// parseLiteral parses a literal (including language tag or datatype)
+func parseLiteral(value string) (term Term, rest string, err error) {
+	term.Kind = Literal
+
+	// find the closing quote
+	end := -1
+	for i := 1; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == '"' {
+			end = i
+			break
+		}
+	}
+	if end == -1 {
+		return term, "", errUnclosedLiteral
+	}
+
+	if term.Value, err = unescape(value[1:end]); err != nil {
+		return term, "", err
+	}
+	rest = value[end+1:]
+
+	switch {
+	case strings.HasPrefix(rest, "@"):
+		end := strings.IndexAny(rest, " \t.")
+		if end == -1 {
+			end = len(rest)
+		}
+		// a language tag may contain '-', but never '.'
+		term.Language = rest[1:end]
+		rest = rest[end:]
+	case strings.HasPrefix(rest, "^^"):
+		term.Datatype, rest, err = parseIRI(rest[2:])
+		if err != nil {
+			return term, "", err
+		}
+		if term.Datatype == XSDString {
+			term.Datatype = ""
+		}
+	}
+	return term, rest, nil
+}
+
+// unescape resolves escape sequences inside iris and literals
+func unescape(value string) (string, error) {
+	if !strings.ContainsRune(value, '\\') {
+		return value, nil
+	}
+
+	var builder strings.Builder
+	builder.Grow(len(value))
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c != '\\' {
+			builder.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i >= len(value) {
+			return "", errUnexpectedEnd
+		}
+		switch value[i] {
+		case 't':
+			builder.WriteByte('\t')
+		case 'b':
+			builder.WriteByte('\b')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'f':
+			builder.WriteByte('\f')
+		case '"', '\'', '\\':
+			builder.WriteByte(value[i])
+		case 'u', 'U':
+			size := 4
+			if value[i] == 'U' {
+				size = 8
+			}
+			if i+size >= len(value) {
+				return "", errUnexpectedEnd
+			}
+			code, err := strconv.ParseUint(value[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape sequence: %w", err)
+			}
+			builder.WriteRune(rune(code))
+			i += size
+		default:
+			return "", fmt.Errorf("invalid escape sequence '\\%c'", value[i])
+		}
+	}
+
+	result := builder.String()
+	if !utf8.ValidString(result) {
+		return "", fmt.Errorf("invalid utf-8 in escape sequence")
+	}
+	return result, nil
+}
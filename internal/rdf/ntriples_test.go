@@ -0,0 +1,77 @@
+package rdf
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseStatement(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Triple
+		wantErr bool
+	}{
+		{
+			line: `<http://example.com/s> <http://example.com/p> <http://example.com/o> .`,
+			want: Triple{NewIRI("http://example.com/s"), NewIRI("http://example.com/p"), NewIRI("http://example.com/o")},
+		},
+		{
+			line: `_:b0 <http://example.com/p> "hello \"world\""@en-US .`,
+			want: Triple{NewBlankNode("b0"), NewIRI("http://example.com/p"), Term{Kind: Literal, Value: `hello "world"`, Language: "en-US"}},
+		},
+		{
+			line: `<http://example.com/s> <http://example.com/p> "42"^^<http://www.w3.org/2001/XMLSchema#integer> <http://example.com/g> .`,
+			want: Triple{NewIRI("http://example.com/s"), NewIRI("http://example.com/p"), NewLiteral("42", XSDNamespace+"integer")},
+		},
+		{
+			line: `<http://example.com/s> <http://example.com/p> "plain"^^<http://www.w3.org/2001/XMLSchema#string>.`,
+			want: Triple{NewIRI("http://example.com/s"), NewIRI("http://example.com/p"), NewLiteral("plain", "")},
+		},
+		{
+			line: `<http://example.com/s> <http://example.com/p> "ä" . # comment`,
+			want: Triple{NewIRI("http://example.com/s"), NewIRI("http://example.com/p"), NewLiteral("ä", "")},
+		},
+		{line: `"literal" <http://example.com/p> <http://example.com/o> .`, wantErr: true},
+		{line: `<http://example.com/s> _:p <http://example.com/o> .`, wantErr: true},
+		{line: `<http://example.com/s> <http://example.com/p> <http://example.com/o>`, wantErr: true},
+		{line: `<http://example.com/s> <http://example.com/p> "unclosed .`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := parseStatement(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseStatement() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseStatement() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTriple_String(t *testing.T) {
+	tests := []struct {
+		triple Triple
+		want   string
+	}{
+		{
+			triple: Triple{NewIRI("http://example.com/s"), NewIRI("http://example.com/p"), NewLiteral("line\nbreak", "")},
+			want:   `<http://example.com/s> <http://example.com/p> "line\nbreak" .`,
+		},
+		{
+			triple: Triple{NewBlankNode("b0"), NewIRI("http://example.com/p"), NewLiteral("42", XSDNamespace+"integer")},
+			want:   `_:b0 <http://example.com/p> "42"^^<http://www.w3.org/2001/XMLSchema#integer> .`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.triple.String(); got != tt.want {
+				t.Errorf("Triple.String() = %v, want %v", got, tt.want)
+			}
+			if got, err := parseStatement(tt.want); err != nil || !reflect.DeepEqual(got, tt.triple) {
+				t.Errorf("parseStatement(Triple.String()) = %v, %v, want %v", got, err, tt.triple)
+			}
+		})
+	}
+}
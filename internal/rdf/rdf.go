@@ -0,0 +1,140 @@
+// Package rdf provides a minimal representation of rdf terms and triples.
+//
+// It is intended for reading and writing line-based rdf dumps only, not as a general purpose rdf library.
+package rdf
+
+// cspell:words rdf xsd
+
+import (
+	"strings"
+)
+
+// Common vocabulary used throughout drincw
+const (
+	RDFNamespace = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+	XSDNamespace = "http://www.w3.org/2001/XMLSchema#"
+
+	Type       = RDFNamespace + "type"
+	LangString = RDFNamespace + "langString"
+	XSDString  = XSDNamespace + "string"
+)
+
+// Kind represents the kind of an rdf term
+type Kind int
+
+const (
+	IRI Kind = iota + 1
+	BlankNode
+	Literal
+)
+
+// Term represents a single rdf term.
+//
+// The zero value represents the absence of a term.
+type Term struct {
+	Kind Kind
+
+	Value    string // iri, blank node label (without "_:") or lexical form of the literal
+	Datatype string // datatype iri of a literal (empty for plain literals)
+	Language string // language tag of a literal (if any)
+}
+
+// NewIRI returns a new term representing the given IRI
+func NewIRI(iri string) Term {
+	return Term{Kind: IRI, Value: iri}
+}
+
+// NewBlankNode returns a new term representing the blank node with the given label
+func NewBlankNode(label string) Term {
+	return Term{Kind: BlankNode, Value: label}
+}
+
+// NewLiteral returns a new literal term with the given datatype.
+// An empty datatype represents a simple literal.
+func NewLiteral(value, datatype string) Term {
+	return Term{Kind: Literal, Value: value, Datatype: datatype}
+}
+
+// IsResource checks if this term is an IRI or a blank node
+func (t Term) IsResource() bool {
+	return t.Kind == IRI || t.Kind == BlankNode
+}
+
+// LiteralDatatype returns the effective datatype of a literal term.
+// Simple literals have datatype xsd:string, language-tagged literals rdf:langString.
+func (t Term) LiteralDatatype() string {
+	switch {
+	case t.Kind != Literal:
+		return ""
+	case t.Language != "":
+		return LangString
+	case t.Datatype == "":
+		return XSDString
+	default:
+		return t.Datatype
+	}
+}
+
+// String formats this term in N-Triples syntax
+func (t Term) String() string {
+	var builder strings.Builder
+	writeTerm(&builder, t)
+	return builder.String()
+}
+
+// Triple represents an rdf triple
+type Triple struct {
+	Subject   Term
+	Predicate Term
+	Object    Term
+}
+
+// String formats this triple as a single N-Triples line (without a trailing newline)
+func (t Triple) String() string {
+	return t.Subject.String() + " " + t.Predicate.String() + " " + t.Object.String() + " ."
+}
+
+func writeTerm(builder *strings.Builder, t Term) {
+	switch t.Kind {
+	case IRI:
+		builder.WriteRune('<')
+		builder.WriteString(escapeIRI(t.Value))
+		builder.WriteRune('>')
+	case BlankNode:
+		builder.WriteString("_:")
+		builder.WriteString(t.Value)
+	case Literal:
+		builder.WriteRune('"')
+		builder.WriteString(escapeLiteral(t.Value))
+		builder.WriteRune('"')
+		switch {
+		case t.Language != "":
+			builder.WriteRune('@')
+			builder.WriteString(t.Language)
+		case t.Datatype != "" && t.Datatype != XSDString:
+			builder.WriteString("^^<")
+			builder.WriteString(escapeIRI(t.Datatype))
+			builder.WriteRune('>')
+		}
+	}
+}
+
+var literalEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\"", "\\\"",
+	"\n", "\\n",
+	"\r", "\\r",
+)
+
+func escapeLiteral(value string) string {
+	return literalEscaper.Replace(value)
+}
+
+var iriEscaper = strings.NewReplacer(
+	">", "\\u003E",
+	"\\", "\\u005C",
+)
+
+func escapeIRI(value string) string {
+	return iriEscaper.Replace(value)
+}
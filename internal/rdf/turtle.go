@@ -0,0 +1,96 @@
+package rdf
+
+// cspell:words rdfs
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Prefixes maps prefix names to namespace iris
+type Prefixes map[string]string
+
+// Well known prefixes
+const (
+	RDFSNamespace  = "http://www.w3.org/2000/01/rdf-schema#"
+	SHACLNamespace = "http://www.w3.org/ns/shacl#"
+)
+
+// Names returns the names of all prefixes in sorted order
+func (p Prefixes) Names() []string {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Turtle formats the prefixes as a sequence of Turtle '@prefix' directives
+func (p Prefixes) Turtle() string {
+	var builder strings.Builder
+	for _, name := range p.Names() {
+		builder.WriteString("@prefix ")
+		builder.WriteString(name)
+		builder.WriteString(": <")
+		builder.WriteString(escapeIRI(p[name]))
+		builder.WriteString("> .\n")
+	}
+	return builder.String()
+}
+
+// Compact compacts an iri into a prefixed name using the longest matching prefix.
+// If no prefix matches, or the result would not be a valid prefixed name, returns false.
+func (p Prefixes) Compact(iri string) (name string, ok bool) {
+	var prefix, namespace string
+	for n, ns := range p {
+		if !strings.HasPrefix(iri, ns) || len(ns) <= len(namespace) {
+			continue
+		}
+		prefix, namespace = n, ns
+	}
+	if namespace == "" {
+		return "", false
+	}
+
+	local := strings.TrimPrefix(iri, namespace)
+	if !isLocalName(local) {
+		return "", false
+	}
+	return prefix + ":" + local, true
+}
+
+// isLocalName checks if value can be used as the local part of a prefixed name.
+// It is intentionally more conservative than the Turtle grammar.
+func isLocalName(value string) bool {
+	for i, r := range value {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+		case (r == '-' || r == '.') && i > 0:
+		default:
+			return false
+		}
+	}
+	return !strings.HasSuffix(value, ".")
+}
+
+// FormatIRI formats an iri in Turtle syntax, compacting it if possible
+func (p Prefixes) FormatIRI(iri string) string {
+	if name, ok := p.Compact(iri); ok {
+		return name
+	}
+	return NewIRI(iri).String()
+}
+
+// FormatTerm formats a term in Turtle syntax, compacting iris if possible
+func (p Prefixes) FormatTerm(t Term) string {
+	switch {
+	case t.Kind == IRI:
+		return p.FormatIRI(t.Value)
+	case t.Kind == Literal && t.Language == "" && t.Datatype != "" && t.Datatype != XSDString:
+		return `"` + escapeLiteral(t.Value) + `"^^` + p.FormatIRI(t.Datatype)
+	default:
+		return t.String()
+	}
+}
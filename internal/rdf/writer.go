@@ -0,0 +1,80 @@
+package rdf
+
+// cspell:words ntriples
+
+import (
+	"fmt"
+	"io"
+)
+
+// TripleWriter writes triples one after another
+type TripleWriter interface {
+	Write(t Triple) error
+}
+
+// NTriplesWriter writes triples as N-Triples, one per line
+type NTriplesWriter struct {
+	w io.Writer
+}
+
+// NewNTriplesWriter creates a new writer writing N-Triples to w
+func NewNTriplesWriter(w io.Writer) *NTriplesWriter {
+	return &NTriplesWriter{w: w}
+}
+
+// Write writes a single triple
+func (nw *NTriplesWriter) Write(t Triple) error {
+	_, err := fmt.Fprintln(nw.w, t)
+	return err
+}
+
+// TurtleWriter writes triples in Turtle syntax.
+//
+// Consecutive triples with the same subject are grouped using ';'.
+// Close must be called to terminate the last statement.
+type TurtleWriter struct {
+	w        io.Writer
+	prefixes Prefixes
+
+	subject Term // subject of the open statement, if any
+}
+
+// NewTurtleWriter creates a new writer that writes the given prefixes and then triples to w
+func NewTurtleWriter(w io.Writer, prefixes Prefixes) (*TurtleWriter, error) {
+	if _, err := io.WriteString(w, prefixes.Turtle()); err != nil {
+		return nil, err
+	}
+	return &TurtleWriter{w: w, prefixes: prefixes}, nil
+}
+
+// Write writes a single triple
+func (tw *TurtleWriter) Write(t Triple) (err error) {
+	predicate := tw.prefixes.FormatTerm(t.Predicate)
+	if t.Predicate.Value == Type {
+		predicate = "a"
+	}
+	object := tw.prefixes.FormatTerm(t.Object)
+
+	if tw.subject == t.Subject {
+		_, err = fmt.Fprintf(tw.w, " ;\n    %s %s", predicate, object)
+		return err
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+
+	tw.subject = t.Subject
+	_, err = fmt.Fprintf(tw.w, "\n%s %s %s", tw.prefixes.FormatTerm(t.Subject), predicate, object)
+	return err
+}
+
+// Close terminates the open statement, if any.
+// It does not close the underlying writer.
+func (tw *TurtleWriter) Close() error {
+	if tw.subject == (Term{}) {
+		return nil
+	}
+	tw.subject = Term{}
+	_, err := io.WriteString(tw.w, " .\n")
+	return err
+}
@@ -0,0 +1,38 @@
+package rdf
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTurtleWriter(t *testing.T) {
+	var builder strings.Builder
+	tw, err := NewTurtleWriter(&builder, Prefixes{"ex": "http://example.com/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, triple := range []Triple{
+		{NewIRI("http://example.com/s"), NewIRI(Type), NewIRI("http://example.com/Class")},
+		{NewIRI("http://example.com/s"), NewIRI("http://example.com/p"), NewLiteral("42", XSDNamespace+"integer")},
+		{NewIRI("http://other.com/s"), NewIRI("http://example.com/p"), NewLiteral("hello", "")},
+	} {
+		if err := tw.Write(triple); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := `@prefix ex: <http://example.com/> .
+
+ex:s a ex:Class ;
+    ex:p "42"^^<http://www.w3.org/2001/XMLSchema#integer> .
+
+<http://other.com/s> ex:p "hello" .
+`
+	if got := builder.String(); got != want {
+		t.Errorf("TurtleWriter wrote\n%s\nwant\n%s", got, want)
+	}
+}
@@ -12,48 +12,65 @@ import (
 	"golang.org/x/exp/slices"
 )
 
-// Builder provides a correspondence between bundle ids and TableBuilder.
+// Builder provides a correspondence between bundle ids and the TableBuilders importing each bundle.
 //
 // New values should be created using make().
 // The zero value does not cause panic(), but can not hold and correspondences
-type Builder map[string]TableBuilder
+type Builder map[string]TableBuilders
+
+// TableBuilders holds the TableBuilders importing a single bundle.
+// Each TableBuilder produces a separate table, allowing a bundle to be imported from several sql tables.
+type TableBuilders []TableBuilder
 
 // NewBuilder creates a new builder from a pathbuilder.
 //
-// Each bundle in the pathbuilder will correspond to a new TableBuilder.
+// Each bundle in the pathbuilder will correspond to a single new TableBuilder.
 // See BundleBuilder for details.
 func NewBuilder(pb pathbuilder.Pathbuilder) Builder {
 	bundles := pb.Bundles()
-	b := make(map[string]TableBuilder, len(bundles))
+	b := make(map[string]TableBuilders, len(bundles))
 	for _, bundle := range bundles {
-		b[bundle.MachineName()] = NewTableBuilder(*bundle)
+		b[bundle.MachineName()] = TableBuilders{NewTableBuilder(*bundle)}
 	}
 	return b
 }
 
 // Apply updates the provided ODBC instance tables with correspondences provided within this Builder.
+// Each table is replaced by one table for every corresponding TableBuilder.
 // Tables that do not have any correspondence will be removed from server.
+//
+// Generated sql uses the MariaDB dialect, see ApplyDialect.
 func (b Builder) Apply(server *odbc.Server) error {
-	tables := make([]odbc.Table, 0, len(server.Tables))
-	orders := make(map[string]int, len(server.Tables))
+	return b.ApplyDialect(server, MariaDB)
+}
+
+// ApplyDialect is like Apply, but generates sql in the given dialect.
+func (b Builder) ApplyDialect(server *odbc.Server, dialect *Dialect) error {
+	type orderedTable struct {
+		table odbc.Table
+		order int
+	}
+
+	tables := make([]orderedTable, 0, len(server.Tables))
 	for _, table := range server.Tables {
-		bb, ok := b[table.Name]
-		if !ok {
-			continue
-		}
-		orders[bb.TableName] = bb.Order
-		if err := bb.Apply(&table); err != nil {
-			return err
+		for _, tb := range b[table.Name] {
+			applied := table
+			if err := tb.ApplyDialect(&applied, dialect); err != nil {
+				return err
+			}
+			tables = append(tables, orderedTable{table: applied, order: tb.Order})
 		}
-		tables = append(tables, table)
 	}
 
 	// re-sort the tables by the provided order
-	slices.SortStableFunc(tables, func(x, y odbc.Table) int {
-		return orders[x.Name] - orders[y.Name]
+	slices.SortStableFunc(tables, func(x, y orderedTable) int {
+		return x.order - y.order
 	})
 
-	server.Tables = tables
+	server.Tables = make([]odbc.Table, len(tables))
+	for i, ot := range tables {
+		server.Tables[i] = ot.table
+	}
 
 	return nil
 }
@@ -92,8 +109,18 @@ func NewTableBuilder(bundle pathbuilder.Bundle) TableBuilder {
 // Apply updates the provided ODBC table with correspondences provided within this Builder.
 //
 // Bundles inside a table that do not have a corresponding sql in this TableBuilder will be removed.
+//
+// Generated sql uses the MariaDB dialect, see ApplyDialect.
 func (tb TableBuilder) Apply(table *odbc.Table) error {
+	return tb.ApplyDialect(table, MariaDB)
+}
+
+// ApplyDialect is like Apply, but generates sql in the given dialect.
+func (tb TableBuilder) ApplyDialect(table *odbc.Table, dialect *Dialect) error {
 	table.Name = tb.TableName
+	if tb.ID != "" {
+		table.ID = tb.ID
+	}
 
 	selectors := make(map[string]Selector)
 	names := make(map[string]string)
@@ -117,7 +144,11 @@ func (tb TableBuilder) Apply(table *odbc.Table) error {
 	table.Row.Fields = fields
 
 	var err error
-	table.Select, table.Append, err = tb.build(selectors, names)
+	delimiter := table.Delimiter
+	if delimiter == "" {
+		delimiter = defaultDelimiter
+	}
+	table.Select, table.Append, err = tb.build(dialect.orDefault(), delimiter, selectors, names)
 	if err != nil {
 		return err
 	}
@@ -173,12 +204,20 @@ func (tb TableBuilder) applyField(field *odbc.Field, selectors map[string]Select
 // The append statement represents an arbitrary sql statement that should be appended to the sql statement as a whole.
 //
 // Either SQL statement is escaped and can be safely inserted inside an sql statement.
+// Generated sql uses the MariaDB dialect, see BuildDialect.
 func (tb TableBuilder) Build() (selectS, appendS string, err error) {
-	return tb.build(tb.Fields, nil)
+	return tb.BuildDialect(MariaDB)
 }
 
-func (tb TableBuilder) build(fields map[string]Selector, names map[string]string) (selects, appends string, err error) {
-	var selectorS, appendS []string
+// BuildDialect is like Build, but generates sql in the given dialect.
+// Aggregated values are separated by the default delimiter ";".
+func (tb TableBuilder) BuildDialect(dialect *Dialect) (selectS, appendS string, err error) {
+	return tb.build(dialect.orDefault(), defaultDelimiter, tb.Fields, nil)
+}
+
+func (tb TableBuilder) build(dialect *Dialect, delimiter string, fields map[string]Selector, names map[string]string) (selects, appends string, err error) {
+	var selectorS []string
+	joins := newJoinSet(dialect)
 
 	// generate a consistent ordering for the fields
 	keys := make([]string, 0, len(fields))
@@ -198,22 +237,37 @@ func (tb TableBuilder) build(fields map[string]Selector, names map[string]string
 		if name == "" {
 			name = key
 		}
-		name = Identifier(names[key]).Quoted()
+		name = dialect.QuoteIdentifier(Identifier(name))
+
+		// selectors that can share their joins add them to the set of joins directly
+		if joiner, ok := fields[key].(joiningSelector); ok {
+			s, err := joiner.joinExpression(dialect, bbTable, temp, joins)
+			if err != nil {
+				return "", "", err
+			}
+			selectorS = append(selectorS, fmt.Sprintf("%s as %s", s, name))
+			continue
+		}
 
-		s, err := fields[key].selectExpression(bbTable, temp)
+		s, err := fields[key].selectExpression(dialect, bbTable, temp)
 		if err != nil {
 			return "", "", err
 		}
 		selectorS = append(selectorS, fmt.Sprintf("%s as %s", s, name))
 
-		a, err := fields[key].appendStatement(bbTable, temp)
+		var a string
+		if aggregator, ok := fields[key].(aggregatingSelector); ok {
+			a, err = aggregator.aggregateStatement(dialect, bbTable, temp, delimiter)
+		} else {
+			a, err = fields[key].appendStatement(dialect, bbTable, temp)
+		}
 		if err == errSelectorNoAppend {
 			continue
 		}
 		if err != nil {
 			return "", "", err
 		}
-		appendS = append(appendS, a)
+		joins.add(a)
 	}
 
 	selectPrefix := ""
@@ -221,5 +275,5 @@ func (tb TableBuilder) build(fields map[string]Selector, names map[string]string
 		selectPrefix = "DISTINCT "
 	}
 
-	return selectPrefix + strings.Join(selectorS, ", "), strings.Join(appendS, " "), nil
+	return selectPrefix + strings.Join(selectorS, ", "), strings.Join(joins.statements, " "), nil
 }
@@ -0,0 +1,242 @@
+package sql
+
+// cspell:words mariadb postgres sqlite
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Dialect describes the flavor of sql understood by a specific database.
+//
+// Statements generated by this package are MariaDB unless a different dialect is requested.
+// A nil *Dialect is equivalent to MariaDB.
+type Dialect struct {
+	Name string // name of the dialect, as used in selectors files and on the command line
+
+	Quote    rune                // rune used to quote identifiers
+	Keywords map[string]struct{} // reserved words (in lower case) that must be quoted when used as identifiers
+
+	// FoldsCase indicates that unquoted identifiers are folded to lower case.
+	// Identifiers containing upper case letters then need to be quoted.
+	FoldsCase bool
+
+	// LooseNames indicates that unquoted identifiers may start with a digit and contain '$'.
+	LooseNames bool
+
+	// aggregate returns an expression that aggregates values into a single string, as described by agg.
+	aggregate func(agg aggregation) string
+}
+
+// aggregation describes the aggregation of the values of an expression within a group into a single string
+type aggregation struct {
+	Expression string // expression to aggregate
+	Separator  string // separator to place between values
+
+	OrderBy    string // optional expression to order values by
+	Descending bool   // order values descending instead of ascending
+}
+
+// orderBy returns the ORDER BY clause of this aggregation, prefixed by a space.
+// If values are not ordered, returns the empty string.
+func (agg aggregation) orderBy() string {
+	if agg.OrderBy == "" {
+		return ""
+	}
+	if agg.Descending {
+		return " ORDER BY " + agg.OrderBy + " DESC"
+	}
+	return " ORDER BY " + agg.OrderBy
+}
+
+// MariaDB is the dialect used by MariaDB and MySQL, and the default of this package
+var MariaDB = &Dialect{
+	Name:       "mariadb",
+	Quote:      '`',
+	Keywords:   restrictedKeywords,
+	LooseNames: true,
+	aggregate: func(agg aggregation) string {
+		return fmt.Sprintf("GROUP_CONCAT(%s%s SEPARATOR %s)", agg.Expression, agg.orderBy(), doubleQuotedLiteral(agg.Separator))
+	},
+}
+
+// PostgreSQL is the dialect used by PostgreSQL
+var PostgreSQL = &Dialect{
+	Name:      "postgres",
+	Quote:     '"',
+	Keywords:  postgresKeywords,
+	FoldsCase: true,
+	aggregate: func(agg aggregation) string {
+		return fmt.Sprintf("STRING_AGG(CAST(%s AS TEXT), %s%s)", agg.Expression, stringLiteral(agg.Separator), agg.orderBy())
+	},
+}
+
+// SQLite is the dialect used by SQLite
+var SQLite = &Dialect{
+	Name:     "sqlite",
+	Quote:    '"',
+	Keywords: sqliteKeywords,
+	aggregate: func(agg aggregation) string {
+		return fmt.Sprintf("GROUP_CONCAT(%s, %s%s)", agg.Expression, stringLiteral(agg.Separator), agg.orderBy())
+	},
+}
+
+// Dialects contains all known dialects
+var Dialects = []*Dialect{MariaDB, PostgreSQL, SQLite}
+
+// DialectNames returns the names of all known dialects
+func DialectNames() []string {
+	names := make([]string, len(Dialects))
+	for i, dialect := range Dialects {
+		names[i] = dialect.Name
+	}
+	return names
+}
+
+// DialectByName returns the dialect with the given name (ignoring case)
+func DialectByName(name string) (*Dialect, error) {
+	for _, dialect := range Dialects {
+		if strings.EqualFold(dialect.Name, name) {
+			return dialect, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown sql dialect %q (known dialects: %s)", name, strings.Join(DialectNames(), ", "))
+}
+
+// orDefault returns dialect, or MariaDB if dialect is nil
+func (dialect *Dialect) orDefault() *Dialect {
+	if dialect == nil {
+		return MariaDB
+	}
+	return dialect
+}
+
+// String returns the name of this dialect
+func (dialect *Dialect) String() string {
+	return dialect.orDefault().Name
+}
+
+// MarshalJSON marshals this dialect as its name
+func (dialect *Dialect) MarshalJSON() ([]byte, error) {
+	return json.Marshal(dialect.String())
+}
+
+// UnmarshalJSON un-marshals a dialect from its name
+func (dialect *Dialect) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return err
+	}
+	found, err := DialectByName(name)
+	if err != nil {
+		return err
+	}
+	*dialect = *found
+	return nil
+}
+
+// QuoteIdentifier quotes identifier for use within a statement of this dialect
+func (dialect *Dialect) QuoteIdentifier(identifier Identifier) string {
+	dialect = dialect.orDefault()
+	valid, _, count := identifier.check(dialect)
+	if !valid {
+		return string(identifier)
+	}
+	return identifier.quote(dialect.Quote, count)
+}
+
+// EscapeIdentifier escapes identifier for use within a statement of this dialect.
+// It only quotes the identifier if necessary.
+func (dialect *Dialect) EscapeIdentifier(identifier Identifier) string {
+	dialect = dialect.orDefault()
+	valid, needsQuote, count := identifier.check(dialect)
+	if !valid || !needsQuote {
+		return string(identifier)
+	}
+	return identifier.quote(dialect.Quote, count)
+}
+
+// Aggregate returns an expression aggregating the values of expression within a group into a single string.
+// Values are separated by separator.
+func (dialect *Dialect) Aggregate(expression string, separator string) string {
+	return dialect.orDefault().aggregate(aggregation{Expression: expression, Separator: separator})
+}
+
+// Sprintf is like fmt.Sprintf, except that Identifier arguments are formatted using this dialect.
+// See Identifier.Format for the supported verbs.
+func (dialect *Dialect) Sprintf(format string, args ...interface{}) string {
+	dialect = dialect.orDefault()
+	for i, arg := range args {
+		if identifier, ok := arg.(Identifier); ok {
+			args[i] = dialectIdentifier{dialect: dialect, identifier: identifier}
+		}
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// dialectIdentifier formats an identifier using a specific dialect
+type dialectIdentifier struct {
+	dialect    *Dialect
+	identifier Identifier
+}
+
+func (di dialectIdentifier) Format(f fmt.State, verb rune) {
+	switch verb {
+	case 's':
+		f.Write([]byte(di.dialect.EscapeIdentifier(di.identifier)))
+	case 'q':
+		f.Write([]byte(di.dialect.QuoteIdentifier(di.identifier)))
+	default:
+		fmt.Fprintf(f, "%"+string(verb), string(di.identifier))
+	}
+}
+
+// stringLiteral quotes value as an sql string literal using single quotes
+func stringLiteral(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// doubleQuotedLiteral quotes value as a MariaDB string literal using double quotes
+func doubleQuotedLiteral(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// postgresKeywords contains the reserved words of PostgreSQL, including those that can not be used as column names
+var postgresKeywords = keywords(
+	"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric", "authorization", "binary", "both",
+	"case", "cast", "check", "collate", "collation", "column", "concurrently", "constraint", "create", "cross",
+	"current_catalog", "current_date", "current_role", "current_schema", "current_time", "current_timestamp", "current_user",
+	"default", "deferrable", "desc", "distinct", "do", "else", "end", "except", "false", "fetch", "for", "foreign", "freeze",
+	"from", "full", "grant", "group", "having", "ilike", "in", "initially", "inner", "intersect", "into", "is", "isnull",
+	"join", "lateral", "leading", "left", "like", "limit", "localtime", "localtimestamp", "natural", "not", "notnull", "null",
+	"offset", "on", "only", "or", "order", "outer", "overlaps", "placing", "primary", "references", "returning", "right",
+	"select", "session_user", "similar", "some", "symmetric", "table", "tablesample", "then", "to", "trailing", "true",
+	"union", "unique", "user", "using", "variadic", "verbose", "when", "where", "window", "with",
+)
+
+// sqliteKeywords contains the keywords of SQLite.
+// Many of them may be used unquoted in some contexts, but quoting them is always safe.
+var sqliteKeywords = keywords(
+	"abort", "action", "add", "after", "all", "alter", "always", "analyze", "and", "as", "asc", "attach", "autoincrement",
+	"before", "begin", "between", "by", "cascade", "case", "cast", "check", "collate", "column", "commit", "conflict",
+	"constraint", "create", "cross", "current", "current_date", "current_time", "current_timestamp", "database", "default",
+	"deferrable", "deferred", "delete", "desc", "detach", "distinct", "do", "drop", "each", "else", "end", "escape", "except",
+	"exclude", "exclusive", "exists", "explain", "fail", "filter", "first", "following", "for", "foreign", "from", "full",
+	"generated", "glob", "group", "groups", "having", "if", "ignore", "immediate", "in", "index", "indexed", "initially",
+	"inner", "insert", "instead", "intersect", "into", "is", "isnull", "join", "key", "last", "left", "like", "limit", "match",
+	"materialized", "natural", "no", "not", "nothing", "notnull", "null", "nulls", "of", "offset", "on", "or", "order",
+	"others", "outer", "over", "partition", "plan", "pragma", "preceding", "primary", "query", "raise", "range", "recursive",
+	"references", "regexp", "reindex", "release", "rename", "replace", "restrict", "returning", "right", "rollback", "row",
+	"rows", "savepoint", "select", "set", "table", "temp", "temporary", "then", "ties", "to", "transaction", "trigger",
+	"unbounded", "union", "unique", "update", "using", "vacuum", "values", "view", "virtual", "when", "where", "window",
+	"with", "without",
+)
+
+func keywords(words ...string) map[string]struct{} {
+	set := make(map[string]struct{}, len(words))
+	for _, word := range words {
+		set[word] = struct{}{}
+	}
+	return set
+}
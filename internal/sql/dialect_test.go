@@ -0,0 +1,150 @@
+package sql
+
+// cspell:words mariadb postgres sqlite odbc dbsql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/odbc"
+
+	_ "modernc.org/sqlite"
+)
+
+func TestDialect_QuoteIdentifier(t *testing.T) {
+	tests := []struct {
+		dialect     *Dialect
+		identifier  Identifier
+		wantQuoted  string
+		wantEscaped string
+	}{
+		{MariaDB, "Name", "`Name`", "Name"},
+		{MariaDB, "user", "`user`", "user"},
+		{MariaDB, "a$b", "`a$b`", "a$b"},
+
+		{PostgreSQL, "name", `"name"`, "name"},
+		{PostgreSQL, "Name", `"Name"`, `"Name"`},
+		{PostgreSQL, "user", `"user"`, `"user"`},
+		{PostgreSQL, "1st", `"1st"`, `"1st"`},
+		{PostgreSQL, `a"b`, `"a""b"`, `"a""b"`},
+
+		{SQLite, "Name", `"Name"`, "Name"},
+		{SQLite, "pragma", `"pragma"`, `"pragma"`},
+		{SQLite, "a`b", "\"a`b\"", "\"a`b\""},
+
+		{nil, "join", "`join`", "`join`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.String()+"/"+string(tt.identifier), func(t *testing.T) {
+			if got := tt.dialect.QuoteIdentifier(tt.identifier); got != tt.wantQuoted {
+				t.Errorf("Dialect.QuoteIdentifier() = %v, want %v", got, tt.wantQuoted)
+			}
+			if got := tt.dialect.EscapeIdentifier(tt.identifier); got != tt.wantEscaped {
+				t.Errorf("Dialect.EscapeIdentifier() = %v, want %v", got, tt.wantEscaped)
+			}
+		})
+	}
+}
+
+var many2many = &Many2ManySelector{
+	Column:          "title",
+	Table:           "events",
+	Through:         "participation",
+	TheirKey:        "id",
+	TheirThroughKey: "event",
+	OurThroughKey:   "person",
+	OurKey:          "id",
+}
+
+func TestMany2ManySelector_dialects(t *testing.T) {
+	tests := []struct {
+		dialect    *Dialect
+		wantAppend string
+	}{
+		{MariaDB, "LEFT JOIN (SELECT `participation`.`person` AS `t_through_id`, GROUP_CONCAT(`events`.`title` SEPARATOR \";\") AS `t_through_value` FROM `participation` LEFT JOIN `events` ON `participation`.`event` = `events`.`id` GROUP BY `participation`.`person`) AS `t_through` ON `t_through`.`t_through_id` = `people`.`id`"},
+		{PostgreSQL, `LEFT JOIN (SELECT "participation"."person" AS "t_through_id", STRING_AGG(CAST("events"."title" AS TEXT), ';') AS "t_through_value" FROM "participation" LEFT JOIN "events" ON "participation"."event" = "events"."id" GROUP BY "participation"."person") AS "t_through" ON "t_through"."t_through_id" = "people"."id"`},
+		{SQLite, `LEFT JOIN (SELECT "participation"."person" AS "t_through_id", GROUP_CONCAT("events"."title", ';') AS "t_through_value" FROM "participation" LEFT JOIN "events" ON "participation"."event" = "events"."id" GROUP BY "participation"."person") AS "t_through" ON "t_through"."t_through_id" = "people"."id"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name, func(t *testing.T) {
+			got, err := many2many.appendStatement(tt.dialect, "people", "t")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantAppend {
+				t.Errorf("Many2ManySelector.appendStatement() = %v, want %v", got, tt.wantAppend)
+			}
+		})
+	}
+}
+
+// openSQLite opens a new in-memory sqlite database, and executes the given statements in it
+func openSQLite(t *testing.T, statements ...string) *dbsql.DB {
+	t.Helper()
+
+	db, err := dbsql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1) // every connection has its own in-memory database
+
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+func TestDialect_sqlite(t *testing.T) {
+	ctx := context.Background()
+
+	db := openSQLite(t,
+		`CREATE TABLE people (id INTEGER, "Name" TEXT)`,
+		`CREATE TABLE events (id INTEGER, title TEXT)`,
+		`CREATE TABLE participation (person INTEGER, event INTEGER)`,
+		`INSERT INTO people VALUES (1, 'Alice'), (2, 'Bob')`,
+		`INSERT INTO events VALUES (1, 'Party'), (2, 'Meeting')`,
+		`INSERT INTO participation VALUES (1, 1), (1, 2)`,
+	)
+
+	tb := TableBuilder{
+		TableName: "people",
+		ID:        "id",
+		Fields: map[string]Selector{
+			"name":   &ColumnSelector{Column: "Name"},
+			"events": many2many,
+		},
+	}
+	var table odbc.Table
+	table.Row.Fields = []odbc.Field{{ID: "name", FieldName: "name"}, {ID: "events", FieldName: "events"}}
+	if err := tb.ApplyDialect(&table, SQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	rows, err := db.QueryContext(ctx, SQLite.ForTable(table)+" ORDER BY 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	var got [][3]string
+	for rows.Next() {
+		var id, name string
+		var events dbsql.NullString
+		if err := rows.Scan(&id, &events, &name); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, [3]string{id, name, events.String})
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][3]string{{"1", "Alice", "Party;Meeting"}, {"2", "Bob", ""}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("query returned %v, want %v", got, want)
+	}
+}
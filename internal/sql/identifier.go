@@ -47,7 +47,7 @@ func (identifier Identifier) Escaped() string {
 //
 // If value is not a valid identifier (neither quoted nor unquoted), returns it unchanged and ok=false.
 func (identifier Identifier) Escape() (escaped string, ok bool) {
-	valid, needsQuote, count := identifier.check()
+	valid, needsQuote, count := identifier.check(MariaDB)
 	if !valid {
 		return string(identifier), false
 	}
@@ -56,15 +56,19 @@ func (identifier Identifier) Escape() (escaped string, ok bool) {
 		return string(identifier), true
 	}
 
-	return identifier.quote(count), true
+	return identifier.quote(RUNE_QUOTE, count), true
 }
 
+// Quote quotes this identifier into a string safe for usage within a MariaDB query.
+// To quote for a different database, see Dialect.QuoteIdentifier.
+//
+// If value is not a valid identifier, returns it unchanged and ok=false.
 func (identifier Identifier) Quote() (quoted string, ok bool) {
-	valid, _, count := identifier.check()
+	valid, _, count := identifier.check(MariaDB)
 	if !valid {
 		return string(identifier), false
 	}
-	return identifier.quote(count), true
+	return identifier.quote(RUNE_QUOTE, count), true
 }
 
 var builderPool = &sync.Pool{
@@ -75,11 +79,11 @@ var builderPool = &sync.Pool{
 
 var RUNE_QUOTE = '`'
 
-// quote quotes an identifier without performing any internal checks.
+// quote quotes an identifier using the quote rune without performing any internal checks.
 //
 // guess should contain the number of quotes inside the identifier.
 // It is used only for optimization purposes
-func (identifier Identifier) quote(guess int) string {
+func (identifier Identifier) quote(quote rune, guess int) string {
 	// grab a new builder from the pool
 	builder := builderPool.Get().(*strings.Builder)
 	builder.Reset()
@@ -87,15 +91,15 @@ func (identifier Identifier) quote(guess int) string {
 
 	builder.Grow(len(identifier) + 2 + guess)
 
-	// iterate over the builder, and quote only the "`" character
-	builder.WriteRune(RUNE_QUOTE)
+	// iterate over the builder, and quote only the quote character
+	builder.WriteRune(quote)
 	for _, r := range identifier {
-		if r == RUNE_QUOTE {
-			builder.WriteRune(RUNE_QUOTE)
+		if r == quote {
+			builder.WriteRune(quote)
 		}
 		builder.WriteRune(r)
 	}
-	builder.WriteRune(RUNE_QUOTE)
+	builder.WriteRune(quote)
 
 	return builder.String()
 }
@@ -105,13 +109,13 @@ var restrictedKeywords = map[string]struct{}{
 	"add": {}, "all": {}, "alter": {}, "and": {}, "any": {}, "as": {}, "asc": {}, "avg": {}, "backup": {}, "between": {}, "by": {}, "case": {}, "check": {}, "column": {}, "constraint": {}, "count": {}, "create": {}, "database": {}, "default": {}, "delete": {}, "desc": {}, "distinct": {}, "drop": {}, "exec": {}, "exists": {}, "foreign": {}, "from": {}, "full": {}, "group": {}, "having": {}, "in": {}, "index": {}, "inner": {}, "insert": {}, "into": {}, "is": {}, "join": {}, "key": {}, "left": {}, "like": {}, "limit": {}, "max": {}, "min": {}, "not": {}, "null": {}, "or": {}, "order": {}, "outer": {}, "primary": {}, "procedure": {}, "replace": {}, "right": {}, "rownum": {}, "select": {}, "set": {}, "sql": {}, "sum": {}, "table": {}, "top": {}, "truncate": {}, "union": {}, "unique": {}, "update": {}, "values": {}, "view": {}, "where": {},
 }
 
-// check checks if an identifier is valid
+// check checks if an identifier is valid within the given dialect
 // valid indicates if the identifier is valid at all.
 // needsQuote indicates if the identifier needs to be quoted.
 // quoteCharCount indicates the number of characters that need to be prefixed with a quote character.
 //
 // Adapted from https://mariadb.com/kb/en/identifier-names/#quote-character.
-func (identifier Identifier) check() (valid bool, needsQuote bool, quoteCharCount int) {
+func (identifier Identifier) check(dialect *Dialect) (valid bool, needsQuote bool, quoteCharCount int) {
 	// an identifier may not be empty
 	if len(identifier) == 0 {
 		return false, false, 0
@@ -119,7 +123,7 @@ func (identifier Identifier) check() (valid bool, needsQuote bool, quoteCharCoun
 
 	var lastRune rune     // the last rune in the string
 	sawOnlyDigits := true // does the identifier contain only digits?
-	for _, r := range identifier {
+	for i, r := range identifier {
 		// must be part of an identifier
 		if !('\u0001' <= r && r <= '\uffff') {
 			return false, false, 0
@@ -127,7 +131,7 @@ func (identifier Identifier) check() (valid bool, needsQuote bool, quoteCharCoun
 
 		// identifier starting with digits followed by 'e' must be escaped
 		// to prevent confusion with a literal
-		if sawOnlyDigits && (r == 'e') {
+		if sawOnlyDigits && i > 0 && (r == 'e') {
 			needsQuote = true
 		}
 
@@ -138,13 +142,18 @@ func (identifier Identifier) check() (valid bool, needsQuote bool, quoteCharCoun
 		}
 
 		// characters only allowed in quoted identifiers
-		if !(isDigit || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || (r == '$') || (r == '_') || ('\u0080' <= r && r <= '\uffff')) {
+		if !(isDigit || ('a' <= r && r <= 'z') || ('A' <= r && r <= 'Z') || (r == '$' && dialect.LooseNames) || (r == '_') || ('\u0080' <= r && r <= '\uffff')) {
 			needsQuote = true
-			if r == RUNE_QUOTE {
+			if r == dialect.Quote {
 				quoteCharCount++
 			}
 		}
 
+		// upper case letters are lost when the dialect folds case
+		if dialect.FoldsCase && unicode.IsUpper(r) {
+			needsQuote = true
+		}
+
 		lastRune = r
 	}
 
@@ -154,6 +163,11 @@ func (identifier Identifier) check() (valid bool, needsQuote bool, quoteCharCoun
 		return true, true, 0
 	}
 
+	// some dialects do not allow identifiers to start with a digit
+	if first := identifier[0]; !dialect.LooseNames && '0' <= first && first <= '9' {
+		needsQuote = true
+	}
+
 	// an identifier may not end with a space character
 	if unicode.IsSpace(lastRune) {
 		return false, false, 0
@@ -161,7 +175,7 @@ func (identifier Identifier) check() (valid bool, needsQuote bool, quoteCharCoun
 
 	// check for restricted keywords (which aren't already cloned)
 	if !needsQuote {
-		_, ok := restrictedKeywords[strings.ToLower(string(identifier))]
+		_, ok := dialect.Keywords[strings.ToLower(string(identifier))]
 		if ok {
 			needsQuote = true
 		}
@@ -21,14 +21,15 @@ func Test_Identifier_check(t *testing.T) {
 
 		{"join", true, true, 0}, // keyword needs quoting
 
-		{"10e12", true, true, 0}, // things confused with a literal need quoting
+		{"10e12", true, true, 0},   // things confused with a literal need quoting
+		{"events", true, false, 0}, // but words starting with 'e' do not
 
 		{"0000", true, true, 0},  // only numerals
 		{"000a", true, false, 0}, // not only numerals
 	}
 	for _, tt := range tests {
 		t.Run(tt.identifier, func(t *testing.T) {
-			gotValid, gotNeedsQuote, gotQuoteCharCount := Identifier(tt.identifier).check()
+			gotValid, gotNeedsQuote, gotQuoteCharCount := Identifier(tt.identifier).check(MariaDB)
 			if gotValid != tt.wantValid {
 				t.Errorf("checkIdentifier() gotValid = %v, want %v", gotValid, tt.wantValid)
 			}
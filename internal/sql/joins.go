@@ -0,0 +1,57 @@
+package sql
+
+import "fmt"
+
+// joinSet collects the append statements of a single table.
+//
+// Joins added using join are shared: joining the same table on the same keys twice reuses the first join.
+type joinSet struct {
+	dialect *Dialect
+
+	aliases    map[string]Identifier // alias of each join, by joinKey
+	statements []string              // statements in the order they were added
+
+	generated int // number of generated aliases
+}
+
+func newJoinSet(dialect *Dialect) *joinSet {
+	return &joinSet{
+		dialect: dialect,
+		aliases: make(map[string]Identifier),
+	}
+}
+
+// add adds a statement that is never shared
+func (js *joinSet) add(statement string) {
+	js.statements = append(js.statements, statement)
+}
+
+// join left joins table as alias on from.ourKey = alias.theirKey, and returns the alias of the joined table.
+// If the same join was added before, no new join is added, and the alias of the existing join is returned instead.
+//
+// If alias is empty, a new alias is generated.
+// Generated aliases are unique within this set, and never start with the prefix of temporary identifiers of fields.
+func (js *joinSet) join(from, ourKey, table, theirKey, alias Identifier) Identifier {
+	key := joinKey(from, ourKey, table, theirKey)
+	if existing, ok := js.aliases[key]; ok {
+		return existing
+	}
+	if alias == "" {
+		alias = Identifier(fmt.Sprintf("hop_%d", js.generated))
+		js.generated++
+	}
+	js.aliases[key] = alias
+
+	js.add(js.dialect.Sprintf("LEFT JOIN %q AS %q ON %q.%q = %q.%q", table, alias, from, ourKey, alias, theirKey))
+	return alias
+}
+
+// joinKey returns a key uniquely identifying a join
+func joinKey(identifiers ...Identifier) string {
+	// NOTE: quoting makes the key unambiguous, even if identifiers contain the separator
+	key := ""
+	for _, identifier := range identifiers {
+		key += identifier.Quoted() + " "
+	}
+	return key
+}
@@ -1,6 +1,7 @@
 package sql
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -62,6 +63,29 @@ func (tb *TableBuilder) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON marshals these table builders as JSON.
+// A single table builder is marshaled as an object, any other number as an array.
+func (tbs TableBuilders) MarshalJSON() ([]byte, error) {
+	if len(tbs) == 1 {
+		return json.Marshal(tbs[0])
+	}
+	return json.Marshal([]TableBuilder(tbs))
+}
+
+// UnmarshalJSON un-marshals either a single table builder object, or an array of table builders.
+func (tbs *TableBuilders) UnmarshalJSON(data []byte) error {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		return json.Unmarshal(data, (*[]TableBuilder)(tbs))
+	}
+
+	var tb TableBuilder
+	if err := json.Unmarshal(data, &tb); err != nil {
+		return err
+	}
+	*tbs = TableBuilders{tb}
+	return nil
+}
+
 // selectorTypes contains a map from selector identifier to corresponding types
 //
 // It is populated by init; the reflect.Type should be a struct, not a pointer to a struct.
@@ -79,7 +103,30 @@ var MARSHAL_COMMENT_PREFIX = `
 
 {{examples}}
 
+	An expression is a single sql expression, quoted like an identifier, for example:
+
+{{expression}}
+
+	The placeholders {table} and {temp} are replaced by the main table and an identifier unique to the field.
+
+	A one2many aggregates the values of all rows of a child table into a single value, separated by the delimiter of the table.
+	The parts in brackets are optional; distinct values may only be ordered by the selected column.
+
+	A chain joins several tables one after another, and selects a column of the last one.
+	Chains of different fields starting with the same tables share their joins.
+
 	Additionally, tables may be reordered (lowest first) by adding an integer "Order" key to each table.
+	A bundle may be imported from several tables by giving an array of tables instead of a single one.
+
+	Connection settings for the generated file may be given using a "$server" key, for example:
+
+	"$server": {"url": "localhost", "port": 3306, "database": "${DB_NAME}", "user": "${DB_USER}", "password": "${DB_PASSWORD}"}
+
+	Placeholders of the form ${NAME} refer to environment variables.
+	They are kept as-is, unless they are explicitly resolved when generating the file.
+
+	The sql dialect of generated statements may be given using a "$dialect" key, one of {{dialects}}.
+	It defaults to "mariadb".
 */
 `
 
@@ -89,6 +136,9 @@ func init() {
 		(*ColumnSelector)(nil),
 		(*JoinSelector)(nil),
 		(*Many2ManySelector)(nil),
+		(*One2ManySelector)(nil),
+		(*ChainSelector)(nil),
+		(*ExpressionSelector)(nil),
 	}
 
 	//
@@ -111,6 +161,9 @@ func init() {
 		fields[0] = string(name)
 
 		for i, f := range fields {
+			if f == variadicExample || strings.HasPrefix(f, optionalExample) {
+				continue
+			}
 			fields[i] = Identifier(f).Escaped()
 		}
 
@@ -118,7 +171,11 @@ func init() {
 		examples = append(examples, "\t"+strings.Join(fields, " "))
 	}
 
+	expression, _ := MarshalSelector(&ExpressionSelector{Expression: "CONCAT({table}.first, ' ', {table}.last)"})
+
 	MARSHAL_COMMENT_PREFIX = strings.Replace(MARSHAL_COMMENT_PREFIX, "{{examples}}", strings.Join(examples, "\n"), 1)
+	MARSHAL_COMMENT_PREFIX = strings.Replace(MARSHAL_COMMENT_PREFIX, "{{expression}}", "\t"+expression, 1)
+	MARSHAL_COMMENT_PREFIX = strings.Replace(MARSHAL_COMMENT_PREFIX, "{{dialects}}", `"`+strings.Join(DialectNames(), `", "`)+`"`, 1)
 	MARSHAL_COMMENT_PREFIX = strings.ReplaceAll(MARSHAL_COMMENT_PREFIX, "\t", "    ")
 }
 
@@ -153,6 +210,10 @@ func marshalSelectorFields(src Selector) (identifiers []Identifier, err error) {
 		}
 	}()
 
+	if v, ok := src.(variadicSelector); ok {
+		return v.marshalFields(), nil
+	}
+
 	spec := src.fields()
 
 	srcRef := reflect.ValueOf(src).Elem()
@@ -186,7 +247,34 @@ func UnmarshalSelector(data string) (selector Selector, err error) {
 		return nil, err
 	}
 
-	return selector, unmarshalSelectorFields(selector, fields[1:])
+	if err := unmarshalSelectorFields(selector, fields[1:]); err != nil {
+		return selector, err
+	}
+
+	if v, ok := selector.(validator); ok {
+		if err := v.Validate(); err != nil {
+			return selector, fmt.Errorf("Selector %q: %w", selector.name(), err)
+		}
+	}
+	return selector, nil
+}
+
+// variadicExample may be used as the last field of a variadicSelector, to indicate that the preceding fields may be repeated.
+const variadicExample = "..."
+
+// optionalExample starts fields of a variadicSelector that may be omitted
+const optionalExample = "["
+
+// variadicSelector is implemented by selectors with a variable number of fields.
+// These marshal and un-marshal their own fields; their fields() method is only used for documentation.
+type variadicSelector interface {
+	marshalFields() []Identifier
+	unmarshalFields(src []Identifier) error
+}
+
+// validator is implemented by selectors that check their fields after un-marshaling
+type validator interface {
+	Validate() error
 }
 
 func unmarshalSelectorFields(dst Selector, src []Identifier) (err error) {
@@ -198,6 +286,10 @@ func unmarshalSelectorFields(dst Selector, src []Identifier) (err error) {
 		}
 	}()
 
+	if v, ok := dst.(variadicSelector); ok {
+		return v.unmarshalFields(src)
+	}
+
 	spec := dst.fields()
 	if len(spec) != len(src) {
 		return fmt.Errorf("Selector %q expected %d arguments, but got %d", dst.name(), len(spec), len(src))
@@ -41,6 +41,47 @@ func TestMarshalUnmarshalSelector(t *testing.T) {
 			},
 			marsheled: "many2many `Column` `from` `Table` through Through on TheirKey TheirThroughKey OurThroughKey OurKey",
 		},
+		{
+			name: "one2many selector",
+			selector: &One2ManySelector{
+				Column:   "number",
+				Table:    "phones",
+				OurKey:   "id",
+				TheirKey: "person_id",
+			},
+			marsheled: "one2many number `from` phones on id person_id",
+		},
+		{
+			name: "ordered distinct one2many selector",
+			selector: &One2ManySelector{
+				Column:     "number",
+				Table:      "phones",
+				OurKey:     "id",
+				TheirKey:   "person_id",
+				Distinct:   true,
+				OrderBy:    "number",
+				Descending: true,
+			},
+			marsheled: "one2many number `from` phones on id person_id `distinct` `order` `by` number `desc`",
+		},
+		{
+			name: "chain selector",
+			selector: &ChainSelector{
+				Column: "name",
+				Hops: []Hop{
+					{Table: "address", OurKey: "address_id", TheirKey: "id"},
+					{Table: "country", OurKey: "country_id", TheirKey: "id"},
+				},
+			},
+			marsheled: "chain name via address on address_id id via country on country_id id",
+		},
+		{
+			name: "expression selector",
+			selector: &ExpressionSelector{
+				Expression: "CONCAT({table}.first, ' ', {table}.last)",
+			},
+			marsheled: "expression `CONCAT({table}.first, ' ', {table}.last)`",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -0,0 +1,535 @@
+package sql
+
+// cspell:words pathbuilder odbc
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Unmapped is a part of an odbc file that Reverse could not map onto selectors
+type Unmapped struct {
+	Table  string // name of the table
+	Field  string // id of the field, empty when the entire table is affected
+	Reason string // human-readable reason
+}
+
+func (u Unmapped) String() string {
+	if u.Field == "" {
+		return fmt.Sprintf("table %q: %s", u.Table, u.Reason)
+	}
+	return fmt.Sprintf("table %q: field %q: %s", u.Table, u.Field, u.Reason)
+}
+
+// Reverse reconstructs selectors from the select and append statements of the tables in an existing odbc file.
+//
+// Tables and fields are keyed by the machine names of the corresponding bundles and fields in pb.
+// Several tables importing the same bundle result in several table builders for that bundle.
+// Each field is mapped onto a ColumnSelector, JoinSelector or Many2ManySelector where possible.
+// Fields that can not be mapped are omitted from the returned selectors, and reported as unmapped instead.
+func Reverse(server odbc.Server, pb pathbuilder.Pathbuilder) (selectors Selectors, unmapped []Unmapped) {
+	conn := server.Connection()
+	selectors.Server = &conn
+	selectors.Builder = make(Builder, len(server.Tables))
+
+	bundles := make(map[string]string) // machine names by bundle id
+	fields := make(map[string]string)  // machine names by field id
+	var index func(bundle *pathbuilder.Bundle)
+	index = func(bundle *pathbuilder.Bundle) {
+		bundles[bundle.Path.Bundle] = bundle.MachineName()
+		for _, field := range bundle.ChildFields {
+			fields[field.Field] = field.MachineName()
+		}
+		for _, child := range bundle.ChildBundles {
+			index(child)
+		}
+	}
+	for _, bundle := range pb.Bundles() {
+		index(bundle)
+	}
+
+	for order, table := range server.Tables {
+		report := func(field string, err error) {
+			unmapped = append(unmapped, Unmapped{Table: table.Name, Field: field, Reason: err.Error()})
+		}
+
+		name, ok := bundles[table.MainBundleID()]
+		if !ok {
+			report("", fmt.Errorf("bundle %q does not exist in the pathbuilder", table.MainBundleID()))
+			continue
+		}
+
+		rt, err := parseTable(table)
+		if err != nil {
+			report("", err)
+		}
+
+		tb := TableBuilder{
+			TableName: table.Name,
+			ID:        table.ID,
+			Disinct:   rt.distinct,
+			Order:     order,
+			Fields:    make(map[string]Selector),
+		}
+
+		walkFields(table.Row.BundlesAndFields, func(field odbc.Field) {
+			key, ok := fields[field.ID]
+			if !ok {
+				report(field.ID, errReverseUnknownField)
+				return
+			}
+
+			selector, err := rt.selector(field)
+			if err != nil {
+				report(field.ID, err)
+				return
+			}
+			tb.Fields[key] = selector
+		})
+
+		selectors.Builder[name] = append(selectors.Builder[name], tb)
+	}
+
+	return selectors, unmapped
+}
+
+// walkFields calls f for each field in contents and nested bundles
+func walkFields(contents odbc.BundlesAndFields, f func(field odbc.Field)) {
+	for _, field := range contents.Fields {
+		f(field)
+	}
+	for _, bundle := range contents.Bundles {
+		walkFields(bundle.BundlesAndFields, f)
+	}
+}
+
+var (
+	errReverseUnknownField = errors.New("field does not exist in the pathbuilder")
+	errReverseNoColumn     = errors.New("select expression is not a column")
+	errReverseCondition    = errors.New("join condition is not a single equality between the table and the joined alias")
+	errReverseJoinType     = errors.New("only LEFT JOIN is supported")
+	errReverseJoinSource   = errors.New("join source is neither a table nor a subquery")
+	errReverseSubquery     = errors.New("subquery is not of the form SELECT through.key AS id, GROUP_CONCAT(table.column SEPARATOR \";\") AS value FROM through LEFT JOIN table ON ...")
+)
+
+// reverseTable holds the parsed select and append statements of a table
+type reverseTable struct {
+	main     Identifier
+	distinct bool
+
+	columns map[string]reverseColumn   // select expressions by alias
+	joins   map[Identifier]reverseJoin // joins by alias
+}
+
+// reverseColumn is a single parsed select expression
+type reverseColumn struct {
+	qualifier Identifier // empty for unqualified columns
+	column    Identifier
+	err       error // non-nil if the expression is not a column
+}
+
+// reverseJoin is a single parsed join
+type reverseJoin struct {
+	join      *JoinSelector      // set for joins of a table
+	many2many *Many2ManySelector // set for joins of a GROUP_CONCAT subquery
+	value     Identifier         // alias of the GROUP_CONCAT value within the subquery
+	err       error              // non-nil if the join can not be mapped
+}
+
+// selector returns the selector for field
+func (rt reverseTable) selector(field odbc.Field) (Selector, error) {
+	name := field.FieldName
+	if name == "" {
+		name = field.ID
+	}
+
+	column, ok := rt.columns[name]
+	if !ok {
+		return nil, fmt.Errorf("select does not contain a column named %q", name)
+	}
+	if column.err != nil {
+		return nil, column.err
+	}
+
+	if column.qualifier == "" || column.qualifier == rt.main {
+		return &ColumnSelector{Column: column.column}, nil
+	}
+
+	join, ok := rt.joins[column.qualifier]
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("alias %q is not defined in append", string(column.qualifier))
+	case join.err != nil:
+		return nil, fmt.Errorf("join of alias %q: %s", string(column.qualifier), join.err)
+	case join.join != nil:
+		selector := *join.join
+		selector.Column = column.column
+		return &selector, nil
+	case column.column != join.value:
+		return nil, fmt.Errorf("column %q of alias %q is not the GROUP_CONCAT value", string(column.column), string(column.qualifier))
+	default:
+		selector := *join.many2many
+		return &selector, nil
+	}
+}
+
+// parseTable parses the select and append statements of table.
+// A non-nil error indicates that parts of the statements could not be parsed, the returned table is still usable.
+func parseTable(table odbc.Table) (rt reverseTable, err error) {
+	rt.main = Identifier(table.Name)
+	rt.columns = make(map[string]reverseColumn)
+	rt.joins = make(map[Identifier]reverseJoin)
+
+	tokens := Tokenize(table.Select)
+	if len(tokens) > 0 && tokens[0].Keyword("DISTINCT") {
+		rt.distinct = true
+		tokens = tokens[1:]
+	}
+	for _, item := range splitTokens(tokens, func(i int) bool { return tokens[i].Punct(",") }) {
+		alias, column := parseSelectItem(item)
+		if alias != "" {
+			rt.columns[alias] = column
+		}
+	}
+
+	tokens = Tokenize(table.Append)
+	for _, clause := range splitTokens(tokens, func(i int) bool { return startsClause(tokens, i) }) {
+		if len(clause) == 0 {
+			continue
+		}
+		alias, join, ok := parseJoin(clause, rt.main)
+		if !ok {
+			err = fmt.Errorf("append contains a %s clause that is not a join and can not be expressed using selectors", clause[0].Value)
+			continue
+		}
+		switch {
+		case alias != "":
+			rt.joins[alias] = join
+		case join.err != nil:
+			err = fmt.Errorf("append contains a join that can not be parsed: %s", join.err)
+		}
+	}
+
+	return rt, err
+}
+
+// JoinAliases returns the aliases defined by the joins of an append statement, in order.
+// Joins without an alias define the name of their table.
+func JoinAliases(statement string) (aliases []Identifier) {
+	tokens := Tokenize(statement)
+	for _, clause := range splitTokens(tokens, func(i int) bool { return startsClause(tokens, i) }) {
+		if alias, _, ok := parseJoin(clause, ""); ok && alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// parseSelectItem parses a single item of a select statement.
+// alias is empty if the item does not define a name.
+func parseSelectItem(item []Token) (alias string, column reverseColumn) {
+	expression := item
+	switch {
+	case len(item) >= 3 && item[len(item)-2].Keyword("AS"):
+		name, _ := item[len(item)-1].Identifier()
+		alias, expression = string(name), item[:len(item)-2]
+	case len(item) == 2 || len(item) == 4:
+		// implicit alias, as in "table.column alias"
+		name, _ := item[len(item)-1].Identifier()
+		alias, expression = string(name), item[:len(item)-1]
+	}
+
+	qualifier, name, ok := parseColumn(expression)
+	if !ok {
+		return alias, reverseColumn{err: errReverseNoColumn}
+	}
+	if alias == "" {
+		alias = string(name)
+	}
+	return alias, reverseColumn{qualifier: qualifier, column: name}
+}
+
+// parseJoin parses a single join clause of an append statement.
+// ok is false if clause is not a join at all.
+func parseJoin(clause []Token, main Identifier) (alias Identifier, join reverseJoin, ok bool) {
+	// join type
+	left := false
+	for len(clause) > 0 && !clause[0].Keyword("JOIN") {
+		switch {
+		case clause[0].Keyword("LEFT"):
+			left = true
+		case clause[0].Keyword("OUTER"):
+		case clause[0].Keyword("RIGHT"), clause[0].Keyword("INNER"), clause[0].Keyword("CROSS"), clause[0].Keyword("NATURAL"):
+			join.err = errReverseJoinType
+		default:
+			return "", reverseJoin{}, false
+		}
+		clause = clause[1:]
+	}
+	if len(clause) == 0 {
+		return "", reverseJoin{}, false
+	}
+	clause = clause[1:] // JOIN
+	if !left && join.err == nil {
+		join.err = errReverseJoinType
+	}
+
+	// source of the join
+	var table Identifier
+	var subquery []Token
+	if len(clause) > 0 && clause[0].Punct("(") {
+		end := closingParen(clause)
+		if end < 0 {
+			return "", reverseJoin{err: errReverseSubquery}, true
+		}
+		subquery, clause = clause[1:end], clause[end+1:]
+	} else if len(clause) > 0 {
+		table, _ = clause[0].Identifier()
+		clause = clause[1:]
+	}
+
+	// alias of the join
+	alias = table
+	if len(clause) > 0 && clause[0].Keyword("AS") {
+		clause = clause[1:]
+	}
+	if len(clause) > 0 && !clause[0].Keyword("ON") {
+		alias, _ = clause[0].Identifier()
+		clause = clause[1:]
+	}
+	if alias == "" || join.err != nil {
+		return alias, join, true
+	}
+
+	// join condition
+	if len(clause) == 0 || !clause[0].Keyword("ON") {
+		join.err = errReverseCondition
+		return alias, join, true
+	}
+	ourKey, theirKey, ok := parseEquality(clause[1:], main, alias)
+	if !ok {
+		join.err = errReverseCondition
+		return alias, join, true
+	}
+
+	switch {
+	case table != "":
+		join.join = &JoinSelector{Table: table, OurKey: ourKey, TheirKey: theirKey}
+	case subquery != nil:
+		var id Identifier
+		join.many2many, id, join.value, join.err = parseThrough(subquery)
+		if join.err == nil && id != theirKey {
+			join.err = fmt.Errorf("join condition uses %q instead of the subquery id %q", string(theirKey), string(id))
+		}
+		if join.many2many != nil {
+			join.many2many.OurKey = ourKey
+		}
+	default:
+		join.err = errReverseJoinSource
+	}
+	return alias, join, true
+}
+
+// parseThrough parses a GROUP_CONCAT subquery, as generated by Many2ManySelector.
+// The OurKey field of the returned selector is not set.
+func parseThrough(tokens []Token) (selector *Many2ManySelector, id, value Identifier, err error) {
+	if len(tokens) == 0 || !tokens[0].Keyword("SELECT") {
+		return nil, "", "", errReverseSubquery
+	}
+	tokens = tokens[1:]
+
+	// split into the select list and the from clause
+	from := -1
+	depths := tokenDepths(tokens)
+	for i, token := range tokens {
+		if depths[i] == 0 && token.Keyword("FROM") {
+			from = i
+			break
+		}
+	}
+	if from == -1 {
+		return nil, "", "", errReverseSubquery
+	}
+	list, rest := tokens[:from], tokens[from+1:]
+
+	items := splitTokens(list, func(i int) bool { return list[i].Punct(",") })
+	if len(items) != 2 {
+		return nil, "", "", errReverseSubquery
+	}
+
+	// through.key AS id
+	idItem := items[0]
+	if len(idItem) < 3 || !idItem[len(idItem)-2].Keyword("AS") {
+		return nil, "", "", errReverseSubquery
+	}
+	id, _ = idItem[len(idItem)-1].Identifier()
+	idQualifier, ourThroughKey, ok := parseColumn(idItem[:len(idItem)-2])
+	if !ok {
+		return nil, "", "", errReverseSubquery
+	}
+
+	// GROUP_CONCAT(table.column SEPARATOR ";") AS value
+	valueItem := items[1]
+	if len(valueItem) < 6 || !valueItem[0].Keyword("GROUP_CONCAT") || !valueItem[1].Punct("(") || !valueItem[len(valueItem)-2].Keyword("AS") {
+		return nil, "", "", errReverseSubquery
+	}
+	value, _ = valueItem[len(valueItem)-1].Identifier()
+	end := closingParen(valueItem[1:]) + 1
+	if end <= 0 {
+		return nil, "", "", errReverseSubquery
+	}
+	concat := valueItem[2:end]
+
+	separator := ","
+	if n := len(concat); n >= 2 && concat[n-2].Keyword("SEPARATOR") && concat[n-1].Kind == StringToken {
+		separator = concat[n-1].Value
+		concat = concat[:n-2]
+	}
+	if separator != ";" {
+		return nil, "", "", fmt.Errorf("GROUP_CONCAT uses separator %q instead of %q", separator, ";")
+	}
+	table, column, ok := parseColumn(concat)
+	if !ok || table == "" {
+		return nil, "", "", errReverseSubquery
+	}
+
+	// FROM through LEFT JOIN table ON through.key = table.key [GROUP BY ...]
+	if len(rest) < 5 {
+		return nil, "", "", errReverseSubquery
+	}
+	through, _ := rest[0].Identifier()
+	if through != idQualifier || !rest[1].Keyword("LEFT") || !rest[2].Keyword("JOIN") || !rest[4].Keyword("ON") {
+		return nil, "", "", errReverseSubquery
+	}
+	if joined, _ := rest[3].Identifier(); joined != table {
+		return nil, "", "", errReverseSubquery
+	}
+
+	condition := rest[5:]
+	for i, token := range condition {
+		if token.Keyword("GROUP") {
+			condition = condition[:i]
+			break
+		}
+	}
+	theirThroughKey, theirKey, ok := parseEquality(condition, through, table)
+	if !ok {
+		return nil, "", "", errReverseSubquery
+	}
+
+	return &Many2ManySelector{
+		Column:          column,
+		Table:           table,
+		Through:         through,
+		TheirKey:        theirKey,
+		TheirThroughKey: theirThroughKey,
+		OurThroughKey:   ourThroughKey,
+	}, id, value, nil
+}
+
+// parseColumn parses tokens of the form "column" or "qualifier.column"
+func parseColumn(tokens []Token) (qualifier, column Identifier, ok bool) {
+	switch {
+	case len(tokens) == 1:
+		column, ok = tokens[0].Identifier()
+		return "", column, ok
+	case len(tokens) == 3 && tokens[1].Punct("."):
+		qualifier, ok = tokens[0].Identifier()
+		if !ok {
+			return "", "", false
+		}
+		column, ok = tokens[2].Identifier()
+		return qualifier, column, ok
+	}
+	return "", "", false
+}
+
+// parseEquality parses tokens of the form "a.x = b.y", where a and b are ours and theirs in any order.
+// Returns the columns belonging to ours and theirs.
+func parseEquality(tokens []Token, ours, theirs Identifier) (ourColumn, theirColumn Identifier, ok bool) {
+	if len(tokens) != 7 || !tokens[3].Punct("=") {
+		return "", "", false
+	}
+	leftQualifier, leftColumn, lok := parseColumn(tokens[:3])
+	rightQualifier, rightColumn, rok := parseColumn(tokens[4:])
+	if !lok || !rok {
+		return "", "", false
+	}
+
+	switch {
+	case leftQualifier == ours && rightQualifier == theirs:
+		return leftColumn, rightColumn, true
+	case leftQualifier == theirs && rightQualifier == ours:
+		return rightColumn, leftColumn, true
+	}
+	return "", "", false
+}
+
+// startsClause checks if tokens[i] starts a new clause of an append statement
+func startsClause(tokens []Token, i int) bool {
+	token := tokens[i]
+	switch {
+	case token.Keyword("LEFT"), token.Keyword("RIGHT"), token.Keyword("INNER"), token.Keyword("CROSS"), token.Keyword("NATURAL"):
+	case token.Keyword("WHERE"), token.Keyword("GROUP"), token.Keyword("ORDER"), token.Keyword("LIMIT"), token.Keyword("HAVING"):
+	case token.Keyword("JOIN"):
+		// JOIN only starts a clause when not preceded by a join type
+		if i > 0 {
+			previous := tokens[i-1]
+			return !(previous.Keyword("LEFT") || previous.Keyword("RIGHT") || previous.Keyword("INNER") || previous.Keyword("OUTER") || previous.Keyword("CROSS") || previous.Keyword("NATURAL"))
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// splitTokens splits tokens at every top-level (i.e. not parenthesized) token for which split returns true.
+// Punctuation tokens used for splitting are dropped, other tokens start the next part.
+func splitTokens(tokens []Token, split func(i int) bool) (parts [][]Token) {
+	depths := tokenDepths(tokens)
+
+	start := 0
+	for i, token := range tokens {
+		if depths[i] != 0 || !split(i) {
+			continue
+		}
+		parts = append(parts, tokens[start:i])
+		start = i
+		if token.Kind == PunctToken {
+			start++
+		}
+	}
+	return append(parts, tokens[start:])
+}
+
+// tokenDepths returns the parenthesis depth of each token.
+// Parentheses themselves belong to the outer depth.
+func tokenDepths(tokens []Token) []int {
+	depths := make([]int, len(tokens))
+	depth := 0
+	for i, token := range tokens {
+		if token.Punct(")") && depth > 0 {
+			depth--
+		}
+		depths[i] = depth
+		if token.Punct("(") {
+			depth++
+		}
+	}
+	return depths
+}
+
+// closingParen returns the index of the parenthesis closing tokens[0].
+// If there is no such parenthesis, returns -1.
+func closingParen(tokens []Token) int {
+	depths := tokenDepths(tokens)
+	for i := 1; i < len(tokens); i++ {
+		if depths[i] == 0 && tokens[i].Punct(")") {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,199 @@
+package sql
+
+// cspell:words odbc pathbuilder
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+const reversePathbuilder = `<pathbuilderinterface>
+	<path><id>person</id><weight>0</weight><enabled>1</enabled><group_id>0</group_id><bundle>b_person</bundle><path_array><x>http://example.com/Person</x></path_array><datatype_property>empty</datatype_property><is_group>1</is_group><name>Person</name></path>
+	<path><id>name</id><weight>0</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_name</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/hasName</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Name</name></path>
+	<path><id>birth</id><weight>1</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_birth</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/born</y><x>http://example.com/Date</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Birth</name></path>
+	<path><id>events</id><weight>2</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_events</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/participated</y><x>http://example.com/Event</x></path_array><datatype_property>http://example.com/label</datatype_property><is_group>0</is_group><name>Events</name></path>
+	<path><id>nickname</id><weight>3</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_nickname</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/nick</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Nickname</name></path>
+</pathbuilderinterface>`
+
+func loadReversePathbuilder(t *testing.T) pathbuilder.Pathbuilder {
+	pb, err := pbxml.Unmarshal([]byte(reversePathbuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb
+}
+
+func TestReverse_roundTrip(t *testing.T) {
+	pb := loadReversePathbuilder(t)
+
+	want := Builder{
+		"person": TableBuilders{{
+			TableName: "people",
+			ID:        "id",
+			Disinct:   true,
+			Fields: map[string]Selector{
+				"name":  &ColumnSelector{Column: "name"},
+				"birth": &JoinSelector{Column: "date", Table: "births", OurKey: "id", TheirKey: "person"},
+				"events": &Many2ManySelector{
+					Column:          "title",
+					Table:           "events",
+					Through:         "participation",
+					TheirKey:        "id",
+					TheirThroughKey: "event",
+					OurThroughKey:   "person",
+					OurKey:          "id",
+				},
+			},
+		}},
+	}
+
+	server := odbc.NewServer(pb)
+	if err := want.Apply(&server); err != nil {
+		t.Fatal(err)
+	}
+
+	// round trip through xml, as done when reading a file
+	data, err := xml.Marshal(server)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server = odbc.Server{}
+	if err := xml.Unmarshal(data, &server); err != nil {
+		t.Fatal(err)
+	}
+
+	got, unmapped := Reverse(server, pb)
+	if len(unmapped) != 0 {
+		t.Errorf("Reverse() unmapped = %v, want none", unmapped)
+	}
+	if !reflect.DeepEqual(got.Builder, want) {
+		t.Errorf("Reverse() = %v, want %v", got.Builder, want)
+	}
+	if wantServer := server.Connection(); got.Server == nil || *got.Server != wantServer {
+		t.Errorf("Reverse() Server = %v, want %v", got.Server, wantServer)
+	}
+}
+
+func TestReverse_legacy(t *testing.T) {
+	pb := loadReversePathbuilder(t)
+
+	var server odbc.Server
+	if err := xml.Unmarshal([]byte(`<server>
+	<table>
+		<select>name, b.date birth, CONCAT(nick, '!') AS nickname, e.title AS events</select>
+		<name>people</name>
+		<append>left outer join births b on b.person = people.id INNER JOIN events e ON e.person = people.id WHERE people.visible = 1</append>
+		<id>id</id>
+		<row>
+			<bundle id="b_person">
+				<field id="f_name"><fieldname>name</fieldname></field>
+				<field id="f_birth"><fieldname>birth</fieldname></field>
+				<field id="f_nickname"><fieldname>nickname</fieldname></field>
+				<field id="f_events"><fieldname>events</fieldname></field>
+				<field id="f_unknown"><fieldname>unknown</fieldname></field>
+			</bundle>
+		</row>
+	</table>
+	<table>
+		<select>*</select>
+		<name>more_people</name>
+		<id>id</id>
+		<row><bundle id="b_person"></bundle></row>
+	</table>
+</server>`), &server); err != nil {
+		t.Fatal(err)
+	}
+
+	got, unmapped := Reverse(server, pb)
+
+	wantFields := map[string]Selector{
+		"name":  &ColumnSelector{Column: "name"},
+		"birth": &JoinSelector{Column: "date", Table: "births", OurKey: "id", TheirKey: "person"},
+	}
+	person := got.Builder["person"]
+	if len(person) != 2 || person[1].TableName != "more_people" || person[1].Order != 1 {
+		t.Fatalf("Reverse() Builder = %v, want tables 'people' and 'more_people'", person)
+	}
+	if !reflect.DeepEqual(person[0].Fields, wantFields) {
+		t.Errorf("Reverse() Fields = %v, want %v", person[0].Fields, wantFields)
+	}
+
+	wantUnmapped := []Unmapped{
+		{Table: "people", Reason: "append contains a WHERE clause that is not a join and can not be expressed using selectors"},
+		{Table: "people", Field: "f_nickname", Reason: "select expression is not a column"},
+		{Table: "people", Field: "f_events", Reason: `join of alias "e": only LEFT JOIN is supported`},
+		{Table: "people", Field: "f_unknown", Reason: "field does not exist in the pathbuilder"},
+	}
+	if !reflect.DeepEqual(unmapped, wantUnmapped) {
+		t.Errorf("Reverse() unmapped = %v, want %v", unmapped, wantUnmapped)
+	}
+}
+
+func TestReverse_unterminated(t *testing.T) {
+	pb := loadReversePathbuilder(t)
+
+	var server odbc.Server
+	if err := xml.Unmarshal([]byte(`<server>
+	<table>
+		<select>name, e.title AS events</select>
+		<name>people</name>
+		<append>LEFT JOIN (</append>
+		<id>id</id>
+		<row>
+			<bundle id="b_person">
+				<field id="f_name"><fieldname>name</fieldname></field>
+				<field id="f_events"><fieldname>events</fieldname></field>
+			</bundle>
+		</row>
+	</table>
+</server>`), &server); err != nil {
+		t.Fatal(err)
+	}
+
+	_, unmapped := Reverse(server, pb)
+
+	wantUnmapped := []Unmapped{
+		{Table: "people", Reason: "append contains a join that can not be parsed: " + errReverseSubquery.Error()},
+		{Table: "people", Field: "f_events", Reason: `alias "e" is not defined in append`},
+	}
+	if !reflect.DeepEqual(unmapped, wantUnmapped) {
+		t.Errorf("Reverse() unmapped = %v, want %v", unmapped, wantUnmapped)
+	}
+}
+
+func Test_parseThrough(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"generated", "SELECT `through`.`ours` AS `id`, GROUP_CONCAT(`table`.`column` SEPARATOR \";\") AS `value` FROM `through` LEFT JOIN `table` ON `through`.`theirs` = `table`.`key` GROUP BY `table`.`key`", ""},
+		{"default separator", "SELECT through.ours AS id, GROUP_CONCAT(table.column) AS value FROM through LEFT JOIN table ON table.key = through.theirs", `GROUP_CONCAT uses separator "," instead of ";"`},
+		{"unterminated group concat", "SELECT through.ours AS id, GROUP_CONCAT(table.column AS value", errReverseSubquery.Error()},
+		{"not a group concat", "SELECT through.ours AS id, table.column AS value FROM through LEFT JOIN table ON table.key = through.theirs", errReverseSubquery.Error()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, id, value, err := parseThrough(Tokenize(tt.query))
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("parseThrough() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseThrough() error = %v", err)
+			}
+
+			want := &Many2ManySelector{Column: "column", Table: "table", Through: "through", TheirKey: "key", TheirThroughKey: "theirs", OurThroughKey: "ours"}
+			if !reflect.DeepEqual(selector, want) || id != "id" || value != "value" {
+				t.Errorf("parseThrough() = %v, %q, %q", selector, string(id), string(value))
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package sql
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Selector provides means of selecting a value from an sql table.
@@ -29,21 +30,23 @@ type Selector interface {
 	// selectExpression generates an expression to insert into an sql select statement.
 	// It will be used roughly like:
 	//
-	//   "SELECT " + selectExpression(table, temp) + " AS my_column FROM " + table
+	//   "SELECT " + selectExpression(dialect, table, temp) + " AS my_column FROM " + table
 	//
+	// dialect is the dialect of sql to generate, and is never nil.
 	// table is the name of the primary table.
 	// temp is the name of a temporary identifier that is guaranteed to be unique between different selectors.
-	selectExpression(table Identifier, temp IdentifierFactory) (string, error)
+	selectExpression(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error)
 
 	// appendStatement generates a statement that will be inserted at the end of the sql statement.
 	// when err is
 	// It will be used roughly like:
 	//
-	// "SELECT ... FROM ... " + appendStatement(table, temp)
+	// "SELECT ... FROM ... " + appendStatement(dialect, table, temp)
 	//
+	// dialect is the dialect of sql to generate, and is never nil.
 	// table is the name of the primary table.
 	// temp is the name of a temporary identifier that is guaranteed to be unique between different selectors.
-	appendStatement(table Identifier, temp IdentifierFactory) (string, error)
+	appendStatement(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error)
 }
 
 var errSelectorNoAppend = errors.New("Selector: no append")
@@ -61,15 +64,17 @@ func (*ColumnSelector) fields() []string {
 	return []string{"$Column"}
 }
 
-func (c ColumnSelector) selectExpression(table Identifier, temp IdentifierFactory) (string, error) {
-	return fmt.Sprintf("%q.%q", table, c.Column), nil
+func (c ColumnSelector) selectExpression(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	return dialect.Sprintf("%q.%q", table, c.Column), nil
 }
 
-func (c ColumnSelector) appendStatement(table Identifier, temp IdentifierFactory) (string, error) {
+func (c ColumnSelector) appendStatement(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
 	return "", errSelectorNoAppend
 }
 
-// JoinSelector selects Column from a secondary Table using a (left) join on equality of OurKey and TheirKey
+// JoinSelector selects Column from a secondary Table using a (left) join on equality of OurKey and TheirKey.
+//
+// JoinSelectors (and ChainSelectors) of the same table joining the same Table on the same keys share a single join.
 type JoinSelector struct {
 	Column Identifier
 
@@ -87,17 +92,23 @@ func (*JoinSelector) fields() []string {
 	return []string{"$Column", "from", "$Table", "on", "$OurKey", "$TheirKey"}
 }
 
-func (j JoinSelector) selectExpression(table Identifier, temp IdentifierFactory) (string, error) {
-	return fmt.Sprintf("%q.%q", temp.Get(""), j.Column), nil
+func (j JoinSelector) selectExpression(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	return dialect.Sprintf("%q.%q", temp.Get(""), j.Column), nil
 }
 
-func (j JoinSelector) appendStatement(table Identifier, temp IdentifierFactory) (string, error) {
+func (j JoinSelector) appendStatement(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
 	theirTable := Identifier(j.Table)
 	theirKey := Identifier(j.TheirKey)
 	tempTable := temp.Get("")
 	ourTable := Identifier(table)
 	ourKey := Identifier(j.OurKey)
-	return fmt.Sprintf("LEFT JOIN %q AS %q ON %q.%q = %q.%q", theirTable, tempTable, ourTable, ourKey, tempTable, theirKey), nil
+	return dialect.Sprintf("LEFT JOIN %q AS %q ON %q.%q = %q.%q", theirTable, tempTable, ourTable, ourKey, tempTable, theirKey), nil
+}
+
+// joinExpression shares the join with any other selector joining the same table on the same keys.
+func (j JoinSelector) joinExpression(dialect *Dialect, table Identifier, temp IdentifierFactory, joins *joinSet) (string, error) {
+	alias := joins.join(table, j.OurKey, j.Table, j.TheirKey, temp.Get(""))
+	return dialect.Sprintf("%q.%q", alias, j.Column), nil
 }
 
 // Many2ManySelector selects a many2many relation.
@@ -121,27 +132,365 @@ func (*Many2ManySelector) fields() []string {
 	return []string{"$Column", "from", "$Table", "through", "$Through", "on", "$TheirKey", "$TheirThroughKey", "$OurThroughKey", "$OurKey"}
 }
 
-func (m Many2ManySelector) selectExpression(table Identifier, temp IdentifierFactory) (string, error) {
+func (m Many2ManySelector) selectExpression(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
 	through := temp.Get("through")
 	throughValue := temp.Get("through_value")
 
-	return fmt.Sprintf("%q.%q", through, throughValue), nil
+	return dialect.Sprintf("%q.%q", through, throughValue), nil
 }
 
-func (m Many2ManySelector) appendStatement(table Identifier, temp IdentifierFactory) (string, error) {
+func (m Many2ManySelector) appendStatement(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
 	through := temp.Get("through")
 	throughID := temp.Get("through_id")
 	throughValue := temp.Get("through_value")
 
-	throughSubquery := fmt.Sprintf(
-		"SELECT %q.%q AS %q, GROUP_CONCAT(%q.%q SEPARATOR \"%s\") AS %q FROM %q LEFT JOIN %q ON %q.%q = %q.%q GROUP BY %q.%q",
+	throughSubquery := dialect.Sprintf(
+		"SELECT %q.%q AS %q, %s AS %q FROM %q LEFT JOIN %q ON %q.%q = %q.%q GROUP BY %q.%q",
 		m.Through, m.OurThroughKey, throughID,
-		m.Table, m.Column, ";", throughValue,
+		dialect.Aggregate(dialect.Sprintf("%q.%q", m.Table, m.Column), ";"), throughValue,
 		m.Through, m.Table,
 		m.Through, m.TheirThroughKey,
 		m.Table, m.TheirKey,
-		m.Table, m.OurKey,
+		m.Through, m.OurThroughKey,
+	)
+
+	return dialect.Sprintf("LEFT JOIN (%s) AS %q ON %q.%q = %q.%q", throughSubquery, through, through, throughID, table, m.OurKey), nil
+}
+
+// defaultDelimiter is the delimiter used by aggregating selectors when the delimiter of the table is not known
+const defaultDelimiter = ";"
+
+// aggregatingSelector is implemented by selectors that aggregate several values into a single delimited value.
+//
+// When building a table, aggregateStatement is called instead of appendStatement.
+type aggregatingSelector interface {
+	Selector
+
+	// aggregateStatement is like appendStatement, but separates values using delimiter.
+	aggregateStatement(dialect *Dialect, table Identifier, temp IdentifierFactory, delimiter string) (string, error)
+}
+
+// One2ManySelector selects all values of Column from a child Table, where TheirKey (in Table) equals OurKey (in the main table).
+//
+// Values are aggregated into a single value, separated by the delimiter of the table.
+// They may optionally be restricted to distinct values, and ordered by the OrderBy column of Table.
+type One2ManySelector struct {
+	Column Identifier
+	Table  Identifier
+
+	OurKey   Identifier
+	TheirKey Identifier
+
+	Distinct   bool
+	OrderBy    Identifier
+	Descending bool
+}
+
+func (*One2ManySelector) name() Identifier {
+	return "one2many"
+}
+
+func (*One2ManySelector) fields() []string {
+	return []string{"$Column", "from", "$Table", "on", "$OurKey", "$TheirKey", "[distinct]", "[order by $OrderBy [desc]]"}
+}
+
+var errOne2ManyDistinctOrder = errors.New("Selector \"one2many\": distinct values can only be ordered by the selected column")
+
+func (o *One2ManySelector) marshalFields() []Identifier {
+	identifiers := []Identifier{o.Column, "from", o.Table, "on", o.OurKey, o.TheirKey}
+	if o.Distinct {
+		identifiers = append(identifiers, "distinct")
+	}
+	if o.OrderBy != "" {
+		identifiers = append(identifiers, "order", "by", o.OrderBy)
+		if o.Descending {
+			identifiers = append(identifiers, "desc")
+		}
+	}
+	return identifiers
+}
+
+func (o *One2ManySelector) unmarshalFields(src []Identifier) error {
+	if len(src) < 6 {
+		return fmt.Errorf("Selector %q expected at least %d arguments, but got %d", o.name(), 6, len(src))
+	}
+	if src[1] != "from" || src[3] != "on" {
+		return fmt.Errorf("Selector %q expected COLUMN \"from\" TABLE \"on\", but got %q %q %q %q", o.name(), src[0], src[1], src[2], src[3])
+	}
+	o.Column, o.Table, o.OurKey, o.TheirKey = src[0], src[2], src[4], src[5]
+
+	rest := src[6:]
+	if len(rest) > 0 && rest[0] == "distinct" {
+		o.Distinct = true
+		rest = rest[1:]
+	}
+	if len(rest) >= 3 && rest[0] == "order" && rest[1] == "by" {
+		o.OrderBy = rest[2]
+		rest = rest[3:]
+		if len(rest) > 0 && rest[0] == "desc" {
+			o.Descending = true
+			rest = rest[1:]
+		}
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("Selector %q got unexpected arguments %q", o.name(), rest)
+	}
+	return nil
+}
+
+// Validate checks that distinct values are only ordered by the selected column.
+// Ordering by any other column would be ambiguous when a value occurs several times.
+func (o One2ManySelector) Validate() error {
+	if o.Distinct && o.OrderBy != "" && o.OrderBy != o.Column {
+		return errOne2ManyDistinctOrder
+	}
+	return nil
+}
+
+func (o One2ManySelector) selectExpression(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	return dialect.Sprintf("%q.%q", temp.Get("many"), temp.Get("many_value")), nil
+}
+
+func (o One2ManySelector) appendStatement(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	return o.aggregateStatement(dialect, table, temp, defaultDelimiter)
+}
+
+func (o One2ManySelector) aggregateStatement(dialect *Dialect, table Identifier, temp IdentifierFactory, delimiter string) (string, error) {
+	if err := o.Validate(); err != nil {
+		return "", err
+	}
+
+	many := temp.Get("many")
+	manyID := temp.Get("many_id")
+	manyValue := temp.Get("many_value")
+
+	// distinct values are selected from a subquery, named like the table itself.
+	from := dialect.Sprintf("%q", o.Table)
+	if o.Distinct {
+		from = dialect.Sprintf("(SELECT DISTINCT %q, %q FROM %q) AS %q", o.TheirKey, o.Column, o.Table, o.Table)
+	}
+
+	agg := aggregation{
+		Expression: dialect.Sprintf("%q.%q", o.Table, o.Column),
+		Separator:  delimiter,
+		Descending: o.Descending,
+	}
+	if o.OrderBy != "" {
+		agg.OrderBy = dialect.Sprintf("%q.%q", o.Table, o.OrderBy)
+	}
+
+	manySubquery := dialect.Sprintf(
+		"SELECT %q.%q AS %q, %s AS %q FROM %s GROUP BY %q.%q",
+		o.Table, o.TheirKey, manyID,
+		dialect.orDefault().aggregate(agg), manyValue,
+		from,
+		o.Table, o.TheirKey,
 	)
 
-	return fmt.Sprintf("LEFT JOIN (%s) AS %q ON %q.%q = %q.%q", throughSubquery, through, through, throughID, table, m.OurKey), nil
+	return dialect.Sprintf("LEFT JOIN (%s) AS %q ON %q.%q = %q.%q", manySubquery, many, many, manyID, table, o.OurKey), nil
+}
+
+// ExpressionSelector selects the value of an arbitrary sql expression, such as a function call.
+//
+// The expression may contain the placeholders "{table}" and "{temp}", which are replaced by the (quoted) name of the main table,
+// and a temporary identifier unique to this selector respectively.
+// It must be a single expression; see Validate.
+type ExpressionSelector struct {
+	Expression Identifier
+}
+
+func (*ExpressionSelector) name() Identifier {
+	return "expression"
+}
+
+func (*ExpressionSelector) fields() []string {
+	return []string{"$Expression"}
+}
+
+func (e ExpressionSelector) selectExpression(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	if err := e.Validate(); err != nil {
+		return "", err
+	}
+	return e.expand(dialect.QuoteIdentifier(table), dialect.QuoteIdentifier(temp.Get(""))), nil
+}
+
+func (e ExpressionSelector) appendStatement(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	return "", errSelectorNoAppend
+}
+
+// expand replaces the placeholders in the expression.
+// Placeholders within string literals and quoted identifiers are left as is.
+func (e ExpressionSelector) expand(table, temp string) string {
+	replacer := strings.NewReplacer("{table}", table, "{temp}", temp)
+
+	var builder strings.Builder
+	runes := []rune(string(e.Expression))
+	start := 0
+	for i := 0; i < len(runes); {
+		if r := runes[i]; r != RUNE_QUOTE && r != '\'' && r != '"' {
+			i++
+			continue
+		}
+		builder.WriteString(replacer.Replace(string(runes[start:i])))
+		_, next, _ := scanQuoted(runes, i)
+		builder.WriteString(string(runes[i:next]))
+		i, start = next, next
+	}
+	builder.WriteString(replacer.Replace(string(runes[start:])))
+	return builder.String()
+}
+
+var (
+	errExpressionEmpty       = errors.New("expression is empty")
+	errExpressionPlaceholder = errors.New("expression contains an unknown placeholder, only {table} and {temp} are supported")
+	errExpressionQuote       = errors.New("expression contains an unterminated quote")
+	errExpressionParen       = errors.New("expression contains unbalanced parentheses")
+	errExpressionComment     = errors.New("expression may not contain comments")
+	errExpressionSemicolon   = errors.New("expression may not contain ';'")
+	errExpressionComma       = errors.New("expression may not contain ',' outside of parentheses")
+)
+
+// expressionClauses are keywords that may not occur outside of parentheses in an expression,
+// because they would end the expression, or change the statement it is inserted in.
+var expressionClauses = []string{"SELECT", "FROM", "AS", "JOIN", "WHERE", "GROUP", "HAVING", "ORDER", "LIMIT", "UNION", "INTERSECT", "EXCEPT"}
+
+// Validate checks that the expression is a single sql expression that can be safely inserted into a select statement.
+//
+// The expression may not be empty, must use only known placeholders (outside of quotes), must close all quotes and parentheses,
+// and may not contain comments or semicolons.
+// Outside of parentheses, it may not contain commas or keywords starting a new clause.
+func (e ExpressionSelector) Validate() error {
+	tokens, terminated := tokenize(e.expand("t", "t"))
+	switch {
+	case len(tokens) == 0:
+		return errExpressionEmpty
+	case !terminated:
+		return errExpressionQuote
+	}
+
+	depth := 0
+	for _, token := range tokens {
+		switch {
+		case token.Punct("("):
+			depth++
+		case token.Punct(")"):
+			depth--
+			if depth < 0 {
+				return errExpressionParen
+			}
+		case token.Punct(";"):
+			return errExpressionSemicolon
+		case token.Punct("{"), token.Punct("}"):
+			// braces within string literals or quoted identifiers are part of those tokens, and thus allowed
+			return errExpressionPlaceholder
+		case token.Punct("#"), token.Punct("--"), token.Punct("/*"):
+			return errExpressionComment
+		case depth > 0:
+			continue
+		case token.Punct(","):
+			return errExpressionComma
+		case token.Kind == WordToken:
+			for _, clause := range expressionClauses {
+				if token.Keyword(clause) {
+					return fmt.Errorf("expression may not contain %s outside of parentheses", clause)
+				}
+			}
+		}
+	}
+	if depth != 0 {
+		return errExpressionParen
+	}
+	return nil
+}
+
+// joiningSelector is implemented by selectors that can share joins with other selectors of the same table.
+//
+// When building a table, joinExpression is called instead of selectExpression and appendStatement.
+type joiningSelector interface {
+	Selector
+
+	// joinExpression is like selectExpression, but adds the joins needed by the selector to joins.
+	joinExpression(dialect *Dialect, table Identifier, temp IdentifierFactory, joins *joinSet) (string, error)
+}
+
+// Hop is a single (left) join of a ChainSelector.
+// It joins Table on equality of OurKey (in the previous table) and TheirKey (in Table).
+type Hop struct {
+	Table Identifier
+
+	OurKey   Identifier
+	TheirKey Identifier
+}
+
+// ChainSelector selects Column from the last table of a sequence of (left) joins.
+//
+// The first hop is joined to the main table, every other hop to the table of the preceding hop.
+// Different ChainSelectors of the same table share the joins of a common prefix of their hops.
+// The first hop is also shared with JoinSelectors joining the same table on the same keys.
+type ChainSelector struct {
+	Column Identifier
+	Hops   []Hop
+}
+
+func (*ChainSelector) name() Identifier {
+	return "chain"
+}
+
+func (*ChainSelector) fields() []string {
+	return []string{"$Column", "via", "$Table", "on", "$OurKey", "$TheirKey", "..."}
+}
+
+var errChainNoHops = errors.New("Selector \"chain\": at least one hop is required")
+
+// hopFields are the fields of each hop
+var hopFields = []string{"via", "$Table", "on", "$OurKey", "$TheirKey"}
+
+func (c *ChainSelector) marshalFields() []Identifier {
+	identifiers := make([]Identifier, 0, 1+len(hopFields)*len(c.Hops))
+	identifiers = append(identifiers, c.Column)
+	for _, hop := range c.Hops {
+		identifiers = append(identifiers, "via", hop.Table, "on", hop.OurKey, hop.TheirKey)
+	}
+	return identifiers
+}
+
+func (c *ChainSelector) unmarshalFields(src []Identifier) error {
+	if len(src) < 1+len(hopFields) || (len(src)-1)%len(hopFields) != 0 {
+		return fmt.Errorf("Selector %q expected a column followed by groups of %d arguments, but got %d arguments", c.name(), len(hopFields), len(src))
+	}
+
+	c.Column = src[0]
+	c.Hops = make([]Hop, 0, (len(src)-1)/len(hopFields))
+	for i := 1; i < len(src); i += len(hopFields) {
+		if src[i] != "via" || src[i+2] != "on" {
+			return fmt.Errorf("Selector %q expected \"via\" TABLE \"on\" in position %d, but got %q %q %q", c.name(), i, src[i], src[i+1], src[i+2])
+		}
+		c.Hops = append(c.Hops, Hop{Table: src[i+1], OurKey: src[i+3], TheirKey: src[i+4]})
+	}
+	return nil
+}
+
+func (c ChainSelector) selectExpression(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	return c.joinExpression(dialect, table, temp, newJoinSet(dialect))
+}
+
+func (c ChainSelector) appendStatement(dialect *Dialect, table Identifier, temp IdentifierFactory) (string, error) {
+	joins := newJoinSet(dialect)
+	if _, err := c.joinExpression(dialect, table, temp, joins); err != nil {
+		return "", err
+	}
+	return strings.Join(joins.statements, " "), nil
+}
+
+func (c ChainSelector) joinExpression(dialect *Dialect, table Identifier, temp IdentifierFactory, joins *joinSet) (string, error) {
+	if len(c.Hops) == 0 {
+		return "", errChainNoHops
+	}
+
+	// aliases of hops are generated by joins, as aliases derived from temp could collide with those of other fields
+	alias := table
+	for _, hop := range c.Hops {
+		alias = joins.join(alias, hop.OurKey, hop.Table, hop.TheirKey, "")
+	}
+	return dialect.Sprintf("%q.%q", alias, c.Column), nil
 }
@@ -0,0 +1,379 @@
+package sql
+
+import (
+	"database/sql"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/odbc"
+)
+
+func TestExpressionSelector_Validate(t *testing.T) {
+	tests := []struct {
+		expression string
+		wantErr    string
+	}{
+		{"CONCAT({table}.first, ' ', {table}.last)", ""},
+		{"DATE_FORMAT(born, '%Y-%m-%d')", ""},
+		{"(SELECT COUNT(*) FROM events AS {temp} WHERE {temp}.person = {table}.id)", ""},
+		{"'a; b -- c'", ""},
+		{"CONCAT(name, ' {x}')", ""},
+		{"`{x}`.name", ""},
+		{"{table}.a - -{table}.b", ""},
+		{"{table}.a / *{table}.b", ""},
+
+		{"", errExpressionEmpty.Error()},
+		{"{tables}.name", errExpressionPlaceholder.Error()},
+		{"CONCAT(name, {x})", errExpressionPlaceholder.Error()},
+		{"CONCAT(first, 'last)", errExpressionQuote.Error()},
+		{"CONCAT(first, last", errExpressionParen.Error()},
+		{"first)", errExpressionParen.Error()},
+		{"first -- comment", errExpressionComment.Error()},
+		{"first /* comment */", errExpressionComment.Error()},
+		{"{table}.a--{table}.b", errExpressionComment.Error()},
+		{"first; DROP TABLE people", errExpressionSemicolon.Error()},
+		{"first, last", errExpressionComma.Error()},
+		{"first AS name", "expression may not contain AS outside of parentheses"},
+		{"first FROM people", "expression may not contain FROM outside of parentheses"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			err := ExpressionSelector{Expression: Identifier(tt.expression)}.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("ExpressionSelector.Validate() error = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("ExpressionSelector.Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpressionSelector_selectExpression(t *testing.T) {
+	selector := &ExpressionSelector{Expression: "(SELECT COUNT(*) FROM events AS {temp} WHERE {temp}.person = {table}.id)"}
+
+	tests := []struct {
+		dialect *Dialect
+		want    string
+	}{
+		{MariaDB, "(SELECT COUNT(*) FROM events AS `column_events` WHERE `column_events`.person = `people`.id)"},
+		{PostgreSQL, `(SELECT COUNT(*) FROM events AS "column_events" WHERE "column_events".person = "people".id)`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name, func(t *testing.T) {
+			got, err := selector.selectExpression(tt.dialect, "people", "column_events")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("ExpressionSelector.selectExpression() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// placeholders within quotes are not expanded
+	quoted := &ExpressionSelector{Expression: "CONCAT({table}.name, ' {table}', `{temp}`.x, \"{temp}\")"}
+	got, err := quoted.selectExpression(MariaDB, "people", "column_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "CONCAT(`people`.name, ' {table}', `{temp}`.x, \"{temp}\")"; got != want {
+		t.Errorf("ExpressionSelector.selectExpression() = %v, want %v", got, want)
+	}
+
+	if _, err := UnmarshalSelector("expression `first, last`"); err == nil {
+		t.Error("UnmarshalSelector() did not reject an invalid expression")
+	}
+}
+
+func TestChainSelector_sharedJoins(t *testing.T) {
+	tb := TableBuilder{
+		TableName: "person",
+		ID:        "id",
+		Fields: map[string]Selector{
+			"city": &ChainSelector{Column: "name", Hops: []Hop{
+				{Table: "address", OurKey: "address_id", TheirKey: "id"},
+				{Table: "city", OurKey: "city_id", TheirKey: "id"},
+			}},
+			"country": &ChainSelector{Column: "name", Hops: []Hop{
+				{Table: "address", OurKey: "address_id", TheirKey: "id"},
+				{Table: "city", OurKey: "city_id", TheirKey: "id"},
+				{Table: "country", OurKey: "country_id", TheirKey: "id"},
+			}},
+			"street": &ChainSelector{Column: "street", Hops: []Hop{
+				{Table: "address", OurKey: "address_id", TheirKey: "id"},
+			}},
+		},
+	}
+
+	gotSelect, gotAppend, err := tb.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSelect := "`hop_1`.`name` as `city`, `hop_2`.`name` as `country`, `hop_0`.`street` as `street`"
+	wantAppend := "LEFT JOIN `address` AS `hop_0` ON `person`.`address_id` = `hop_0`.`id` " +
+		"LEFT JOIN `city` AS `hop_1` ON `hop_0`.`city_id` = `hop_1`.`id` " +
+		"LEFT JOIN `country` AS `hop_2` ON `hop_1`.`country_id` = `hop_2`.`id`"
+	if gotSelect != wantSelect {
+		t.Errorf("TableBuilder.Build() select = %v, want %v", gotSelect, wantSelect)
+	}
+	if gotAppend != wantAppend {
+		t.Errorf("TableBuilder.Build() append = %v, want %v", gotAppend, wantAppend)
+	}
+
+	// run the generated sql on an actual database
+	db := openSQLite(t,
+		`CREATE TABLE person (id INTEGER, address_id INTEGER)`,
+		`CREATE TABLE address (id INTEGER, street TEXT, city_id INTEGER)`,
+		`CREATE TABLE city (id INTEGER, name TEXT, country_id INTEGER)`,
+		`CREATE TABLE country (id INTEGER, name TEXT)`,
+		`INSERT INTO person VALUES (1, 1), (2, NULL)`,
+		`INSERT INTO address VALUES (1, 'Main Street', 1)`,
+		`INSERT INTO city VALUES (1, 'Erlangen', 1)`,
+		`INSERT INTO country VALUES (1, 'Germany')`,
+	)
+
+	var city, country, street sql.NullString
+	if err := db.QueryRow("SELECT "+gotSelect+" FROM `person` "+gotAppend+" WHERE `person`.`id` = 1").Scan(&city, &country, &street); err != nil {
+		t.Fatal(err)
+	}
+	if city.String != "Erlangen" || country.String != "Germany" || street.String != "Main Street" {
+		t.Errorf("query returned %v, %v, %v", city, country, street)
+	}
+}
+
+func TestChainSelector_aliases(t *testing.T) {
+	// a chain on field "a" must not use the alias of a join on field "a_1"
+	tb := TableBuilder{
+		TableName: "person",
+		ID:        "id",
+		Fields: map[string]Selector{
+			"a": &ChainSelector{Column: "name", Hops: []Hop{
+				{Table: "address", OurKey: "address_id", TheirKey: "id"},
+				{Table: "city", OurKey: "city_id", TheirKey: "id"},
+			}},
+			"a_1": &JoinSelector{Column: "name", Table: "employer", OurKey: "employer_id", TheirKey: "id"},
+		},
+	}
+
+	gotSelect, gotAppend, err := tb.BuildDialect(SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSelect := `"hop_1"."name" as "a", "column_a_1"."name" as "a_1"`
+	wantAppend := `LEFT JOIN "address" AS "hop_0" ON "person"."address_id" = "hop_0"."id" ` +
+		`LEFT JOIN "city" AS "hop_1" ON "hop_0"."city_id" = "hop_1"."id" ` +
+		`LEFT JOIN "employer" AS "column_a_1" ON "person"."employer_id" = "column_a_1"."id"`
+	if gotSelect != wantSelect {
+		t.Errorf("TableBuilder.BuildDialect() select = %v, want %v", gotSelect, wantSelect)
+	}
+	if gotAppend != wantAppend {
+		t.Errorf("TableBuilder.BuildDialect() append = %v, want %v", gotAppend, wantAppend)
+	}
+
+	db := openSQLite(t,
+		`CREATE TABLE person (id INTEGER, address_id INTEGER, employer_id INTEGER)`,
+		`CREATE TABLE address (id INTEGER, city_id INTEGER)`,
+		`CREATE TABLE city (id INTEGER, name TEXT)`,
+		`CREATE TABLE employer (id INTEGER, name TEXT)`,
+		`INSERT INTO person VALUES (1, 1, 1)`,
+		`INSERT INTO address VALUES (1, 1)`,
+		`INSERT INTO city VALUES (1, 'Erlangen')`,
+		`INSERT INTO employer VALUES (1, 'FAU')`,
+	)
+
+	var city, employer sql.NullString
+	if err := db.QueryRow("SELECT "+gotSelect+` FROM "person" `+gotAppend).Scan(&city, &employer); err != nil {
+		t.Fatal(err)
+	}
+	if city.String != "Erlangen" || employer.String != "FAU" {
+		t.Errorf("query returned %v, %v", city, employer)
+	}
+}
+
+func TestOne2ManySelector_unmarshal(t *testing.T) {
+	tests := []struct {
+		data    string
+		wantErr bool
+	}{
+		{"one2many number from phones on id person_id order by kind", false},
+		{"one2many number from phones on id person_id distinct order by number desc", false},
+		{"one2many number from phones on id", true},
+		{"one2many number in phones on id person_id", true},
+		{"one2many number from phones on id person_id desc", true},
+		{"one2many number from phones on id person_id order by", true},
+		{"one2many number from phones on id person_id distinct order by kind", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.data, func(t *testing.T) {
+			_, err := UnmarshalSelector(tt.data)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("UnmarshalSelector() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOne2ManySelector_dialects(t *testing.T) {
+	phones := &One2ManySelector{Column: "number", Table: "phones", OurKey: "id", TheirKey: "person_id", OrderBy: "kind", Descending: true}
+	distinct := &One2ManySelector{Column: "number", Table: "phones", OurKey: "id", TheirKey: "person_id", Distinct: true}
+
+	tests := []struct {
+		dialect    *Dialect
+		selector   *One2ManySelector
+		wantAppend string
+	}{
+		{MariaDB, phones, "LEFT JOIN (SELECT `phones`.`person_id` AS `t_many_id`, GROUP_CONCAT(`phones`.`number` ORDER BY `phones`.`kind` DESC SEPARATOR \"|\") AS `t_many_value` FROM `phones` GROUP BY `phones`.`person_id`) AS `t_many` ON `t_many`.`t_many_id` = `people`.`id`"},
+		{PostgreSQL, phones, `LEFT JOIN (SELECT "phones"."person_id" AS "t_many_id", STRING_AGG(CAST("phones"."number" AS TEXT), '|' ORDER BY "phones"."kind" DESC) AS "t_many_value" FROM "phones" GROUP BY "phones"."person_id") AS "t_many" ON "t_many"."t_many_id" = "people"."id"`},
+		{SQLite, distinct, `LEFT JOIN (SELECT "phones"."person_id" AS "t_many_id", GROUP_CONCAT("phones"."number", '|') AS "t_many_value" FROM (SELECT DISTINCT "person_id", "number" FROM "phones") AS "phones" GROUP BY "phones"."person_id") AS "t_many" ON "t_many"."t_many_id" = "people"."id"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect.Name, func(t *testing.T) {
+			got, err := tt.selector.aggregateStatement(tt.dialect, "people", "t", "|")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.wantAppend {
+				t.Errorf("One2ManySelector.aggregateStatement() = %v, want %v", got, tt.wantAppend)
+			}
+		})
+	}
+}
+
+func TestOne2ManySelector_sqlite(t *testing.T) {
+	db := openSQLite(t,
+		`CREATE TABLE people (id INTEGER)`,
+		`CREATE TABLE phones (person_id INTEGER, number TEXT, kind INTEGER)`,
+		`INSERT INTO people VALUES (1), (2)`,
+		`INSERT INTO phones VALUES (1, '123', 2), (1, '456', 1), (1, '123', 3)`,
+	)
+
+	tb := TableBuilder{
+		TableName: "people",
+		ID:        "id",
+		Fields: map[string]Selector{
+			"ordered":  &One2ManySelector{Column: "number", Table: "phones", OurKey: "id", TheirKey: "person_id", OrderBy: "kind", Descending: true},
+			"distinct": &One2ManySelector{Column: "number", Table: "phones", OurKey: "id", TheirKey: "person_id", Distinct: true, OrderBy: "number"},
+		},
+	}
+	var table odbc.Table
+	table.Delimiter = "|"
+	table.Row.Fields = []odbc.Field{{ID: "ordered", FieldName: "ordered"}, {ID: "distinct", FieldName: "distinct"}}
+	if err := tb.ApplyDialect(&table, SQLite); err != nil {
+		t.Fatal(err)
+	}
+
+	for id, want := range map[int][2]string{1: {"123|456", "123|123|456"}, 2: {"", ""}} {
+		var distinct, ordered sql.NullString
+		if err := db.QueryRow(SQLite.ForTable(table)+" WHERE \"people\".\"id\" = ?", id).Scan(new(int), &distinct, &ordered); err != nil {
+			t.Fatal(err)
+		}
+		if got := [2]string{distinct.String, ordered.String}; got != want {
+			t.Errorf("query for %d returned %v, want %v", id, got, want)
+		}
+	}
+}
+
+func TestJoinSelector_sharedJoins(t *testing.T) {
+	tb := TableBuilder{
+		TableName: "people",
+		ID:        "id",
+		Fields: map[string]Selector{
+			"born":   &JoinSelector{Column: "date", Table: "births", OurKey: "id", TheirKey: "person"},
+			"place":  &JoinSelector{Column: "place", Table: "births", OurKey: "id", TheirKey: "person"},
+			"mother": &JoinSelector{Column: "name", Table: "people", OurKey: "mother_id", TheirKey: "id"},
+			"father": &JoinSelector{Column: "name", Table: "people", OurKey: "father_id", TheirKey: "id"},
+			"street": &ChainSelector{Column: "street", Hops: []Hop{{Table: "births", OurKey: "id", TheirKey: "person"}}},
+		},
+	}
+
+	gotSelect, gotAppend, err := tb.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	wantSelect := "`column_born`.`date` as `born`, `column_father`.`name` as `father`, `column_mother`.`name` as `mother`, `column_born`.`place` as `place`, `column_born`.`street` as `street`"
+	wantAppend := "LEFT JOIN `births` AS `column_born` ON `people`.`id` = `column_born`.`person` " +
+		"LEFT JOIN `people` AS `column_father` ON `people`.`father_id` = `column_father`.`id` " +
+		"LEFT JOIN `people` AS `column_mother` ON `people`.`mother_id` = `column_mother`.`id`"
+	if gotSelect != wantSelect {
+		t.Errorf("TableBuilder.Build() select = %v, want %v", gotSelect, wantSelect)
+	}
+	if gotAppend != wantAppend {
+		t.Errorf("TableBuilder.Build() append = %v, want %v", gotAppend, wantAppend)
+	}
+
+	// build the same statement with a separate join for every field
+	var selects, appends []string
+	for _, key := range []string{"born", "father", "mother", "place", "street"} {
+		temp := IdentifierFactory("column_" + key)
+		s, err := tb.Fields[key].selectExpression(SQLite, "people", temp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		a, err := tb.Fields[key].appendStatement(SQLite, "people", temp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		selects = append(selects, s)
+		appends = append(appends, a)
+	}
+	separate := "SELECT " + strings.Join(selects, ", ") + " FROM \"people\" " + strings.Join(appends, " ") + " ORDER BY \"people\".\"id\""
+
+	shared, sharedAppend, err := tb.BuildDialect(SQLite)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count := strings.Count(sharedAppend, "JOIN"); count != 3 {
+		t.Errorf("TableBuilder.BuildDialect() has %d joins, want 3", count)
+	}
+
+	// both statements should return the same rows
+	db := openSQLite(t,
+		`CREATE TABLE people (id INTEGER, name TEXT, mother_id INTEGER, father_id INTEGER)`,
+		`CREATE TABLE births (person INTEGER, date TEXT, place TEXT, street TEXT)`,
+		`INSERT INTO people VALUES (1, 'Alice', NULL, NULL), (2, 'Bob', NULL, NULL), (3, 'Carol', 1, 2)`,
+		`INSERT INTO births VALUES (1, '1970-01-01', 'Erlangen', 'Main Street'), (3, '2000-01-01', 'Nuremberg', NULL)`,
+	)
+
+	query := func(statement string) (rows [][5]string) {
+		t.Helper()
+
+		result, err := db.Query(statement)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer result.Close()
+
+		for result.Next() {
+			var values [5]sql.NullString
+			if err := result.Scan(&values[0], &values[1], &values[2], &values[3], &values[4]); err != nil {
+				t.Fatal(err)
+			}
+			var row [5]string
+			for i, value := range values {
+				row[i] = value.String
+			}
+			rows = append(rows, row)
+		}
+		if err := result.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return rows
+	}
+
+	wantRows := query(separate)
+	gotRows := query("SELECT " + shared + " FROM \"people\" " + sharedAppend + " ORDER BY \"people\".\"id\"")
+	if !reflect.DeepEqual(gotRows, wantRows) {
+		t.Errorf("shared joins returned %v, want %v", gotRows, wantRows)
+	}
+	if len(wantRows) != 3 || wantRows[2] != [5]string{"2000-01-01", "Bob", "Alice", "Nuremberg", ""} {
+		t.Errorf("separate joins returned %v", wantRows)
+	}
+}
@@ -0,0 +1,124 @@
+package sql
+
+// cspell:words odbc jsonc
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/odbc"
+	"muzzammil.xyz/jsonc"
+)
+
+// SettingPrefix is the prefix of keys in a selectors file that hold settings instead of bundles.
+// Bundle machine names can never start with this prefix.
+const SettingPrefix = "$"
+
+// ServerKey is the key in a selectors file that holds connection settings
+const ServerKey = SettingPrefix + "server"
+
+// DialectKey is the key in a selectors file that holds the name of the sql dialect
+const DialectKey = SettingPrefix + "dialect"
+
+// Selectors represents the contents of a selectors file.
+//
+// A selectors file is a json object (with comments) mapping bundle machine names to a table builder, or an array of table builders.
+// It may additionally hold connection settings under ServerKey, and the sql dialect under DialectKey.
+type Selectors struct {
+	Server  *odbc.Connection // connection settings, if any
+	Dialect *Dialect         // sql dialect, if any
+	Builder Builder
+}
+
+// LoadSelectors parses a selectors file in json format (with comments)
+func LoadSelectors(data []byte) (selectors Selectors, err error) {
+	err = jsonc.Unmarshal(data, &selectors)
+	return
+}
+
+// MarshalJSON marshals these selectors as a json object
+func (selectors Selectors) MarshalJSON() ([]byte, error) {
+	values := make(map[string]interface{}, len(selectors.Builder)+2)
+	for name, tbs := range selectors.Builder {
+		values[name] = tbs
+	}
+	if selectors.Server != nil {
+		values[ServerKey] = selectors.Server
+	}
+	if selectors.Dialect != nil {
+		values[DialectKey] = selectors.Dialect
+	}
+	return json.Marshal(values)
+}
+
+// UnmarshalJSON un-marshals a json object into these selectors
+func (selectors *Selectors) UnmarshalJSON(data []byte) error {
+	selectors.Server = nil
+	selectors.Dialect = nil
+	if err := json.Unmarshal(data, &selectors.Builder); err != nil {
+		return err
+	}
+
+	var settings struct {
+		Server  *odbc.Connection `json:"$server"`
+		Dialect *Dialect         `json:"$dialect"`
+	}
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return err
+	}
+	selectors.Server = settings.Server
+	selectors.Dialect = settings.Dialect
+	return nil
+}
+
+// UnmarshalJSON un-marshals a json object into this builder.
+// Keys starting with SettingPrefix are ignored.
+func (b *Builder) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+
+	*b = make(Builder, len(values))
+	for name, value := range values {
+		if strings.HasPrefix(name, SettingPrefix) {
+			continue
+		}
+
+		var tbs TableBuilders
+		if err := json.Unmarshal(value, &tbs); err != nil {
+			return err
+		}
+		(*b)[name] = tbs
+	}
+	return nil
+}
+
+// Connection returns the connection settings to use for generated files.
+// These are the default settings, overridden by the settings in these selectors (if any), overridden by the non-empty settings of flags.
+func (selectors Selectors) Connection(flags odbc.Connection) odbc.Connection {
+	conn := odbc.DefaultConnection()
+	if selectors.Server != nil {
+		conn = conn.Merge(*selectors.Server)
+	}
+	return conn.Merge(flags)
+}
+
+// ConnectionDefaults is like Connection, except that the settings in these selectors take precedence over defaults.
+// It is intended for services generating files for others, where the settings of the service are only defaults.
+func (selectors Selectors) ConnectionDefaults(defaults odbc.Connection) odbc.Connection {
+	conn := odbc.DefaultConnection().Merge(defaults)
+	if selectors.Server != nil {
+		conn = conn.Merge(*selectors.Server)
+	}
+	return conn
+}
+
+// SQLDialect returns the dialect to generate sql in.
+// This is flag if it is not nil, otherwise the dialect of these selectors, and finally MariaDB.
+func (selectors Selectors) SQLDialect(flag *Dialect) *Dialect {
+	if flag != nil {
+		return flag
+	}
+	return selectors.Dialect.orDefault()
+}
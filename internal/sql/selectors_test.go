@@ -0,0 +1,111 @@
+package sql
+
+// cspell:words odbc
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/odbc"
+)
+
+func TestLoadSelectors(t *testing.T) {
+	selectors, err := LoadSelectors([]byte(`{
+		// connection settings
+		"$server": {"url": "db.example.com", "database": "${DB_NAME}", "user": "${DB_USER}"},
+		"person": {"table": "people", "id": "id", "fields": {"name": "column name"}}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadSelectors() error = %v", err)
+	}
+
+	wantServer := &odbc.Connection{URL: "db.example.com", Database: "${DB_NAME}", User: "${DB_USER}"}
+	if !reflect.DeepEqual(selectors.Server, wantServer) {
+		t.Errorf("LoadSelectors() Server = %v, want %v", selectors.Server, wantServer)
+	}
+	if len(selectors.Builder) != 1 || len(selectors.Builder["person"]) != 1 || selectors.Builder["person"][0].TableName != "people" {
+		t.Errorf("LoadSelectors() Builder = %v, want only table 'people'", selectors.Builder)
+	}
+
+	// round trip through json
+	data, err := json.Marshal(selectors)
+	if err != nil {
+		t.Fatalf("Selectors.MarshalJSON() error = %v", err)
+	}
+	got, err := LoadSelectors(data)
+	if err != nil {
+		t.Fatalf("LoadSelectors() error = %v", err)
+	}
+	if !reflect.DeepEqual(got, selectors) {
+		t.Errorf("LoadSelectors(MarshalJSON()) = %v, want %v", got, selectors)
+	}
+
+	// flags override the selectors, which override the defaults
+	conn := selectors.Connection(odbc.Connection{User: "admin"})
+	wantConn := odbc.Connection{URL: "db.example.com", Database: "${DB_NAME}", Port: 3306, User: "admin"}
+	if conn != wantConn {
+		t.Errorf("Selectors.Connection() = %v, want %v", conn, wantConn)
+	}
+
+	// selectors override the flags, which override the defaults
+	conn = selectors.ConnectionDefaults(odbc.Connection{URL: "localhost", Port: 3307, User: "admin"})
+	wantConn = odbc.Connection{URL: "db.example.com", Database: "${DB_NAME}", Port: 3307, User: "${DB_USER}"}
+	if conn != wantConn {
+		t.Errorf("Selectors.ConnectionDefaults() = %v, want %v", conn, wantConn)
+	}
+}
+
+func TestLoadSelectors_multipleTables(t *testing.T) {
+	selectors, err := LoadSelectors([]byte(`{
+		"person": [
+			{"table": "people", "id": "id", "fields": {"name": "column name"}, "order": 2},
+			{"table": "authors", "id": "author_id", "fields": {"name": "column full_name"}, "order": 1}
+		],
+		"place": {"table": "places", "id": "id", "fields": {"label": "column label"}}
+	}`))
+	if err != nil {
+		t.Fatalf("LoadSelectors() error = %v", err)
+	}
+	if len(selectors.Builder["person"]) != 2 || len(selectors.Builder["place"]) != 1 {
+		t.Fatalf("LoadSelectors() Builder = %v, want two tables for 'person' and one for 'place'", selectors.Builder)
+	}
+
+	// single tables are marshaled as objects, several as arrays
+	data, err := json.Marshal(selectors)
+	if err != nil {
+		t.Fatalf("Selectors.MarshalJSON() error = %v", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["person"][0] != '[' || raw["place"][0] != '{' {
+		t.Errorf("Selectors.MarshalJSON() = %s, want an array for 'person' and an object for 'place'", data)
+	}
+
+	// each table builder produces a table, sorted by order
+	server := odbc.Server{Tables: []odbc.Table{{Name: "person"}, {Name: "place"}}}
+	for i, name := range []string{"person", "place"} {
+		server.Tables[i].Row.Bundles = []odbc.Bundle{{
+			ID:               "b_" + name,
+			BundlesAndFields: odbc.BundlesAndFields{Fields: []odbc.Field{{ID: "f_" + name, FieldName: map[string]string{"person": "name", "place": "label"}[name]}}},
+		}}
+	}
+	if err := selectors.Builder.Apply(&server); err != nil {
+		t.Fatalf("Builder.Apply() error = %v", err)
+	}
+
+	var got []string
+	for _, table := range server.Tables {
+		got = append(got, table.Name+": "+table.ID+": "+table.Select)
+	}
+	want := []string{
+		"places: id: `places`.`label` as `label`",
+		"authors: author_id: `authors`.`full_name` as `name`",
+		"people: id: `people`.`name` as `name`",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Builder.Apply() = %v, want %v", got, want)
+	}
+}
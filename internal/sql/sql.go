@@ -4,13 +4,17 @@ package sql
 // cspell:words pathbuilder odbc
 
 import (
-	"fmt"
-
 	"github.com/FAU-CDI/drincw/odbc"
 )
 
-// ForTable generates an sql statement used by the importer with the given table
+// ForTable generates an sql statement used by the importer with the given table.
+// Identifiers are quoted using the MariaDB dialect, see Dialect.ForTable.
 func ForTable(table odbc.Table) string {
+	return MariaDB.ForTable(table)
+}
+
+// ForTable generates an sql statement used by the importer with the given table, quoting identifiers using this dialect.
+func (dialect *Dialect) ForTable(table odbc.Table) string {
 	id := Identifier(table.ID)
 	name := Identifier(table.Name)
 
@@ -24,5 +28,5 @@ func ForTable(table odbc.Table) string {
 		append = " " + table.Append
 	}
 
-	return fmt.Sprintf("SELECT %q.%q as %q%s FROM %q%s", name, id, Identifier("id"), sSelect, name, append)
+	return dialect.Sprintf("SELECT %q.%q as %q%s FROM %q%s", name, id, Identifier("id"), sSelect, name, append)
 }
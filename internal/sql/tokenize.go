@@ -0,0 +1,131 @@
+package sql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// TokenKind is the kind of a Token
+type TokenKind int
+
+const (
+	WordToken       TokenKind = iota // an unquoted word, such as a keyword, an identifier or a number
+	IdentifierToken                  // a quoted identifier
+	StringToken                      // a string literal
+	PunctToken                       // a single punctuation character, or the start of a comment ("--" or "/*")
+)
+
+// Token is a single token of an sql statement
+type Token struct {
+	Kind  TokenKind
+	Value string // value of the token, with quotes and escapes removed
+}
+
+// Identifier returns the identifier represented by this token.
+// ok indicates if the token is a quoted identifier, or a word that does not start with a digit.
+//
+// Unquoted keywords are considered identifiers; callers should check for them first.
+func (token Token) Identifier() (identifier Identifier, ok bool) {
+	switch token.Kind {
+	case IdentifierToken:
+		return Identifier(token.Value), true
+	case WordToken:
+		return Identifier(token.Value), !unicode.IsDigit([]rune(token.Value)[0])
+	}
+	return "", false
+}
+
+// Keyword checks if this token is the unquoted word keyword, compared case-insensitively.
+func (token Token) Keyword(keyword string) bool {
+	return token.Kind == WordToken && strings.EqualFold(token.Value, keyword)
+}
+
+// Punct checks if this token is the given punctuation character (or comment start)
+func (token Token) Punct(punct string) bool {
+	return token.Kind == PunctToken && token.Value == punct
+}
+
+// Tokenize splits an sql statement into words, quoted identifiers, string literals and punctuation.
+// Whitespace is dropped.
+//
+// Tokenize never fails; unterminated quotes extend to the end of value.
+func Tokenize(value string) (tokens []Token) {
+	tokens, _ = tokenize(value)
+	return tokens
+}
+
+// tokenize implements Tokenize.
+// terminated indicates if all quotes in value were closed.
+func tokenize(value string) (tokens []Token, terminated bool) {
+	terminated = true
+	runes := []rune(value)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == RUNE_QUOTE:
+			var text string
+			var ok bool
+			text, i, ok = scanQuoted(runes, i)
+			terminated = terminated && ok
+			tokens = append(tokens, Token{Kind: IdentifierToken, Value: text})
+		case r == '\'' || r == '"':
+			var text string
+			var ok bool
+			text, i, ok = scanQuoted(runes, i)
+			terminated = terminated && ok
+			tokens = append(tokens, Token{Kind: StringToken, Value: text})
+		case isCommentStart(runes, i):
+			tokens = append(tokens, Token{Kind: PunctToken, Value: string(runes[i : i+2])})
+			i += 2
+		case isWordRune(r):
+			start := i
+			for i < len(runes) && isWordRune(runes[i]) {
+				i++
+			}
+			tokens = append(tokens, Token{Kind: WordToken, Value: string(runes[start:i])})
+		default:
+			tokens = append(tokens, Token{Kind: PunctToken, Value: string(r)})
+			i++
+		}
+	}
+	return tokens, terminated
+}
+
+// scanQuoted scans a quoted value starting at runes[start].
+// A doubled quote character represents the quote itself; inside string literals a backslash escapes the following character.
+// Returns the unquoted value and the index after the closing quote.
+// If there is no closing quote, the value extends to the end of runes and ok is false.
+func scanQuoted(runes []rune, start int) (value string, next int, ok bool) {
+	quote := runes[start]
+
+	var builder strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && quote != RUNE_QUOTE && i+1 < len(runes):
+			i++
+			builder.WriteRune(runes[i])
+		case runes[i] != quote:
+			builder.WriteRune(runes[i])
+		case i+1 < len(runes) && runes[i+1] == quote:
+			i++
+			builder.WriteRune(quote)
+		default:
+			return builder.String(), i + 1, true
+		}
+	}
+	return builder.String(), len(runes), false
+}
+
+// isCommentStart checks if runes[i] starts a "--" or "/*" comment
+func isCommentStart(runes []rune, i int) bool {
+	if i+1 >= len(runes) {
+		return false
+	}
+	return (runes[i] == '-' && runes[i+1] == '-') || (runes[i] == '/' && runes[i+1] == '*')
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '$' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
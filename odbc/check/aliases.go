@@ -0,0 +1,61 @@
+package check
+
+// cspell:words odbc
+
+import (
+	"fmt"
+
+	"github.com/FAU-CDI/drincw/internal/sql"
+	"github.com/FAU-CDI/drincw/odbc"
+)
+
+// checkAliases checks that every qualified column in the select statement of table
+// refers to the table itself, or to a table or alias defined in the append statement.
+func (c *checker) checkAliases(table odbc.Table) {
+	defined := map[sql.Identifier]struct{}{sql.Identifier(table.Name): {}}
+	for _, alias := range sql.JoinAliases(table.Append) {
+		defined[alias] = struct{}{}
+	}
+
+	reported := make(map[sql.Identifier]struct{})
+	for _, alias := range qualifiers(sql.Tokenize(table.Select)) {
+		if _, ok := defined[alias]; ok {
+			continue
+		}
+		if _, ok := reported[alias]; ok {
+			continue
+		}
+		reported[alias] = struct{}{}
+		c.report(UndefinedAlias, table.MainBundleID(), "", fmt.Sprintf("select references %q, which is not defined in append", string(alias)))
+	}
+}
+
+// qualifiers returns the identifiers used to qualify column names, i.e. those immediately followed by a '.'.
+// Qualifiers within parenthesized subqueries are skipped, as they may refer to aliases defined by the subquery.
+func qualifiers(tokens []sql.Token) (aliases []sql.Identifier) {
+	subquery := 0 // parenthesis depth within a subquery, if any
+	for i, token := range tokens {
+		switch {
+		case subquery > 0 && token.Punct("("):
+			subquery++
+		case subquery > 0 && token.Punct(")"):
+			subquery--
+		case token.Punct("(") && i+1 < len(tokens) && tokens[i+1].Keyword("SELECT"):
+			subquery = 1
+		}
+		if subquery > 0 {
+			continue
+		}
+
+		alias, ok := token.Identifier()
+		if !ok || i+1 >= len(tokens) || !tokens[i+1].Punct(".") {
+			continue
+		}
+		// skip the qualifier of a column within a qualified name, i.e. `b` in `a`.`b`.`c`
+		if i > 0 && tokens[i-1].Punct(".") {
+			continue
+		}
+		aliases = append(aliases, alias)
+	}
+	return aliases
+}
@@ -0,0 +1,194 @@
+// Package check validates an odbc file against a pathbuilder
+package check
+
+// cspell:words odbc pathbuilder fieldname
+
+import (
+	"fmt"
+
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Kind is the kind of an issue
+type Kind string
+
+const (
+	EmptyName         Kind = "empty-name"          // a table has no name
+	EmptyID           Kind = "empty-id"            // a table has no id column
+	DuplicateTable    Kind = "duplicate-table"     // a table with the same name imports the same bundle
+	UnknownBundle     Kind = "unknown-bundle"      // a bundle id does not exist in the pathbuilder
+	MisplacedBundle   Kind = "misplaced-bundle"    // a bundle is not a child bundle of the enclosing bundle
+	UnknownField      Kind = "unknown-field"       // a field id or fieldname does not exist in the pathbuilder
+	FieldNameMismatch Kind = "field-name-mismatch" // a field id and fieldname refer to different fields
+	MisplacedField    Kind = "misplaced-field"     // a field does not belong to the enclosing bundle
+	MissingField      Kind = "missing-field"       // an enabled field of a bundle is not imported
+	UndefinedAlias    Kind = "undefined-alias"     // the select statement references an alias not defined by the table or append statement
+)
+
+// Warning checks if issues of this kind are only warnings, that do not prevent an import
+func (kind Kind) Warning() bool {
+	return kind == MissingField
+}
+
+// Issue is a single issue found in an odbc file
+type Issue struct {
+	Kind   Kind   `json:"kind"`
+	Table  int    `json:"table"`            // index of the table the issue was found in
+	Name   string `json:"name"`             // name of the table the issue was found in
+	Bundle string `json:"bundle,omitempty"` // id of the bundle the issue was found in, if any
+	Field  string `json:"field,omitempty"`  // id of the field the issue was found in, if any
+	Detail string `json:"detail"`           // human-readable details
+}
+
+// Report is the result of checking an odbc file
+type Report struct {
+	Issues []Issue `json:"issues"` // issues in document order
+}
+
+// Errors returns the number of issues that are not warnings
+func (r Report) Errors() (count int) {
+	for _, issue := range r.Issues {
+		if !issue.Kind.Warning() {
+			count++
+		}
+	}
+	return count
+}
+
+// Check validates server against the given pathbuilder.
+//
+// Bundles are identified by their bundle id, fields by their field id and their fieldname (the id of the path).
+func Check(server odbc.Server, pb pathbuilder.Pathbuilder) (r Report) {
+	c := checker{
+		bundles:      make(map[string]*pathbuilder.Bundle),
+		fieldsByID:   make(map[string]fieldInBundle),
+		fieldsByName: make(map[string]fieldInBundle),
+	}
+	for _, bundle := range pb.Bundles() {
+		c.index(bundle)
+	}
+
+	type tableKey struct{ name, bundle string }
+	seen := make(map[tableKey]int)
+
+	for i, table := range server.Tables {
+		c.table, c.name = i, table.Name
+
+		if table.Name == "" {
+			c.report(EmptyName, "", "", "table has no name")
+		}
+		if table.ID == "" {
+			c.report(EmptyID, "", "", "table has no id column")
+		}
+
+		key := tableKey{name: table.Name, bundle: table.MainBundleID()}
+		if first, ok := seen[key]; ok {
+			c.report(DuplicateTable, key.bundle, "", fmt.Sprintf("table %q already imports bundle %q in table %d", key.name, key.bundle, first))
+		} else {
+			seen[key] = i
+		}
+
+		c.checkContents(nil, table.Row.BundlesAndFields)
+		c.checkAliases(table)
+	}
+
+	return c.Report
+}
+
+// fieldInBundle is a field along with the bundle it belongs to
+type fieldInBundle struct {
+	Field  pathbuilder.Field
+	Bundle *pathbuilder.Bundle
+}
+
+type checker struct {
+	Report
+
+	table int    // index of the current table
+	name  string // name of the current table
+
+	bundles      map[string]*pathbuilder.Bundle // bundles by bundle id
+	fieldsByID   map[string]fieldInBundle       // fields by field id
+	fieldsByName map[string]fieldInBundle       // fields by path id
+}
+
+// index adds bundle, its fields and its child bundles to the indexes of c
+func (c *checker) index(bundle *pathbuilder.Bundle) {
+	c.bundles[bundle.Path.Bundle] = bundle
+	for _, field := range bundle.ChildFields {
+		c.fieldsByID[field.Field] = fieldInBundle{Field: field, Bundle: bundle}
+		c.fieldsByName[field.ID] = fieldInBundle{Field: field, Bundle: bundle}
+	}
+	for _, child := range bundle.ChildBundles {
+		c.index(child)
+	}
+}
+
+func (c *checker) report(kind Kind, bundle, field, detail string) {
+	c.Issues = append(c.Issues, Issue{
+		Kind:   kind,
+		Table:  c.table,
+		Name:   c.name,
+		Bundle: bundle,
+		Field:  field,
+		Detail: detail,
+	})
+}
+
+// checkContents checks the bundles and fields within the given bundle.
+// parent is nil for the contents of a row, or for the contents of a bundle not found in the pathbuilder.
+func (c *checker) checkContents(parent *pathbuilder.Bundle, contents odbc.BundlesAndFields) {
+	parentID := ""
+	if parent != nil {
+		parentID = parent.Path.Bundle
+	}
+
+	imported := make(map[string]struct{}, len(contents.Fields))
+	for _, field := range contents.Fields {
+		c.checkField(parent, parentID, field)
+		imported[field.ID] = struct{}{}
+	}
+
+	if parent != nil {
+		for _, field := range parent.ChildFields {
+			if _, ok := imported[field.Field]; ok || !field.Enabled {
+				continue
+			}
+			c.report(MissingField, parentID, field.Field, fmt.Sprintf("field %q (%s) is not imported", field.Field, field.Name))
+		}
+	}
+
+	for _, bundle := range contents.Bundles {
+		found, ok := c.bundles[bundle.ID]
+		switch {
+		case !ok:
+			c.report(UnknownBundle, bundle.ID, "", fmt.Sprintf("bundle %q does not exist", bundle.ID))
+		case parent != nil && found.Parent != parent:
+			c.report(MisplacedBundle, bundle.ID, "", fmt.Sprintf("bundle %q is not a child bundle of %q", bundle.ID, parentID))
+		}
+		c.checkContents(found, bundle.BundlesAndFields)
+	}
+}
+
+func (c *checker) checkField(parent *pathbuilder.Bundle, parentID string, field odbc.Field) {
+	byID, idOK := c.fieldsByID[field.ID]
+	byName, nameOK := c.fieldsByName[field.FieldName]
+
+	switch {
+	case field.ID == "":
+		c.report(UnknownField, parentID, "", fmt.Sprintf("field with fieldname %q has no id", field.FieldName))
+		return
+	case !idOK:
+		c.report(UnknownField, parentID, field.ID, fmt.Sprintf("field %q does not exist", field.ID))
+		return
+	case field.FieldName != "" && !nameOK:
+		c.report(UnknownField, parentID, field.ID, fmt.Sprintf("fieldname %q does not exist", field.FieldName))
+	case field.FieldName != "" && byName.Field.ID != byID.Field.ID:
+		c.report(FieldNameMismatch, parentID, field.ID, fmt.Sprintf("fieldname %q belongs to field %q", field.FieldName, byName.Field.Field))
+	}
+
+	if parent != nil && byID.Bundle != parent {
+		c.report(MisplacedField, parentID, field.ID, fmt.Sprintf("field %q belongs to bundle %q", field.ID, byID.Bundle.Path.Bundle))
+	}
+}
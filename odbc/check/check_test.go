@@ -0,0 +1,164 @@
+package check
+
+// cspell:words odbc pathbuilder fieldname
+
+import (
+	"encoding/xml"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/internal/sql"
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared with the tests of the pathbuilder packages
+var testPathbuilder = filepath.Join("..", "..", "pathbuilder", "testdata", "pathbuilder.xml")
+
+func loadTestPathbuilder(t *testing.T) pathbuilder.Pathbuilder {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb
+}
+
+func TestCheck_generated(t *testing.T) {
+	pb := loadTestPathbuilder(t)
+
+	// round-trip through xml, as done when checking a file
+	data, err := xml.Marshal(odbc.NewServer(pb))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var server odbc.Server
+	if err := xml.Unmarshal(data, &server); err != nil {
+		t.Fatal(err)
+	}
+
+	report := Check(server, pb)
+	if len(report.Issues) != 0 {
+		t.Errorf("Check() reported issues for a generated server: %v", report.Issues)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	pb := loadTestPathbuilder(t)
+
+	var server odbc.Server
+	if err := xml.Unmarshal([]byte(`<server>
+	<table>
+		<select>`+"`person`.`name` AS `p_name`, `births`.`year` AS `p_birth`, `events`.`title`"+`</select>
+		<name>person</name>
+		<append>`+"LEFT JOIN `birth` AS `births` ON `person`.`id` = `births`.`person`"+`</append>
+		<id>id</id>
+		<row>
+			<bundle id="person">
+				<field id="name"><fieldname>name</fieldname></field>
+				<field id="participant_name"><fieldname>birth_year</fieldname></field>
+				<bundle id="participants"></bundle>
+				<bundle id="missing"></bundle>
+			</bundle>
+		</row>
+	</table>
+	<table>
+		<select>*</select>
+		<name>person</name>
+		<id></id>
+		<row>
+			<bundle id="person">
+				<field id="name"><fieldname>name</fieldname></field>
+				<field id="alias"><fieldname>alias</fieldname></field>
+				<field id="birth_year"><fieldname>birth_year</fieldname></field>
+				<field id="birth_place"><fieldname>birth_place</fieldname></field>
+				<field id="knows"><fieldname>knows</fieldname></field>
+				<field id="unknown"><fieldname>unknown</fieldname></field>
+			</bundle>
+		</row>
+	</table>
+</server>`), &server); err != nil {
+		t.Fatal(err)
+	}
+
+	got := Check(server, pb)
+	want := Report{Issues: []Issue{
+		{Kind: FieldNameMismatch, Table: 0, Name: "person", Bundle: "person", Field: "participant_name", Detail: `fieldname "birth_year" belongs to field "birth_year"`},
+		{Kind: MisplacedField, Table: 0, Name: "person", Bundle: "person", Field: "participant_name", Detail: `field "participant_name" belongs to bundle "participants"`},
+		{Kind: MissingField, Table: 0, Name: "person", Bundle: "person", Field: "alias", Detail: `field "alias" (Alias) is not imported`},
+		{Kind: MissingField, Table: 0, Name: "person", Bundle: "person", Field: "birth_year", Detail: `field "birth_year" (Birth Year) is not imported`},
+		{Kind: MissingField, Table: 0, Name: "person", Bundle: "person", Field: "birth_place", Detail: `field "birth_place" (Birth Place) is not imported`},
+		{Kind: MissingField, Table: 0, Name: "person", Bundle: "person", Field: "knows", Detail: `field "knows" (Knows) is not imported`},
+		{Kind: MisplacedBundle, Table: 0, Name: "person", Bundle: "participants", Detail: `bundle "participants" is not a child bundle of "person"`},
+		{Kind: MissingField, Table: 0, Name: "person", Bundle: "participants", Field: "participant_name", Detail: `field "participant_name" (Participant Name) is not imported`},
+		{Kind: UnknownBundle, Table: 0, Name: "person", Bundle: "missing", Detail: `bundle "missing" does not exist`},
+		{Kind: UndefinedAlias, Table: 0, Name: "person", Bundle: "person", Detail: `select references "events", which is not defined in append`},
+		{Kind: EmptyID, Table: 1, Name: "person", Detail: "table has no id column"},
+		{Kind: DuplicateTable, Table: 1, Name: "person", Bundle: "person", Detail: `table "person" already imports bundle "person" in table 0`},
+		{Kind: UnknownField, Table: 1, Name: "person", Bundle: "person", Field: "unknown", Detail: `field "unknown" does not exist`},
+	}}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() got:\n%v\nwant:\n%v", got.Issues, want.Issues)
+	}
+	if errors := got.Errors(); errors != 8 {
+		t.Errorf("Report.Errors() = %d, want %d", errors, 8)
+	}
+}
+
+func Test_qualifiers(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []sql.Identifier
+	}{
+		{"unqualified", "`name`, id AS `x`", nil},
+		{"quoted", "`person`.`name`, `ev``ents`.`title`", []sql.Identifier{"person", "ev`ents"}},
+		{"bare", "person.name, CONCAT(events.title, '.')", []sql.Identifier{"person", "events"}},
+		{"strings and numbers", `'a.b', "c.d", 1.5`, nil},
+		{"database qualified", "db.person.name", []sql.Identifier{"db"}},
+		{"subquery", "person.name, (SELECT MAX(e.date) FROM events e WHERE e.person = person.id) AS last, places.name", []sql.Identifier{"person", "places"}},
+		{"nested subquery", "(SELECT (SELECT x.a FROM x) FROM y), (y.b)", []sql.Identifier{"y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := qualifiers(sql.Tokenize(tt.query)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("qualifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChecker_checkAliases(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string // select statement
+		joins string // append statement
+		want  []string
+	}{
+		{"explicit alias", "births.date", "LEFT JOIN birth AS births ON person.id = births.person", nil},
+		{"implicit alias", "f.name, `b`.`title`", "LEFT JOIN foo f ON person.id = f.person LEFT JOIN `bar` `b` ON person.id = `b`.person", nil},
+		{"table name", "foo.name", "LEFT JOIN foo ON person.id = foo.person", nil},
+		{"aliased table name", "foo.name", "LEFT JOIN foo f ON person.id = f.person", []string{"foo"}},
+		{"subquery alias", "(SELECT MAX(e.date) FROM events e WHERE e.person = person.id), e.date", "", []string{"e"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := checker{}
+			c.checkAliases(odbc.Table{Name: "person", Select: tt.query, Append: tt.joins})
+
+			var got []string
+			for _, issue := range c.Issues {
+				got = append(got, issue.Detail)
+			}
+			var want []string
+			for _, alias := range tt.want {
+				want = append(want, `select references "`+alias+`", which is not defined in append`)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("checkAliases() = %v, want %v", got, want)
+			}
+		})
+	}
+}
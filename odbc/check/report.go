@@ -0,0 +1,35 @@
+package check
+
+import (
+	"fmt"
+	"io"
+)
+
+// WriteText writes a human-readable version of this report to w.
+func (r Report) WriteText(w io.Writer) error {
+	for _, issue := range r.Issues {
+		if _, err := fmt.Fprintln(w, issue); err != nil {
+			return err
+		}
+	}
+
+	warnings := len(r.Issues) - r.Errors()
+	_, err := fmt.Fprintf(w, "%s and %s found\n", pluralize(r.Errors(), "error"), pluralize(warnings, "warning"))
+	return err
+}
+
+// String formats this issue as a single line
+func (issue Issue) String() string {
+	level := "error"
+	if issue.Kind.Warning() {
+		level = "warning"
+	}
+	return fmt.Sprintf("%s: table %d (%q): %s: %s", level, issue.Table, issue.Name, issue.Kind, issue.Detail)
+}
+
+func pluralize(count int, word string) string {
+	if count == 1 {
+		return fmt.Sprintf("%d %s", count, word)
+	}
+	return fmt.Sprintf("%d %ss", count, word)
+}
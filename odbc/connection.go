@@ -0,0 +1,113 @@
+package odbc
+
+// cspell:words odbc
+
+import (
+	"flag"
+	"fmt"
+	"regexp"
+)
+
+// Connection holds the settings used by the importer to connect to the database server.
+//
+// String settings may contain placeholders of the form ${NAME}, referring to environment variables.
+// Placeholders are kept literally, unless they are explicitly resolved using Expand.
+type Connection struct {
+	URL      string `json:"url,omitempty"`
+	Database string `json:"database,omitempty"`
+	Port     int    `json:"port,omitempty"`
+	User     string `json:"user,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// DefaultConnection returns the connection used for new servers
+func DefaultConnection() Connection {
+	return Connection{
+		URL:  "localhost",
+		Port: 3306,
+	}
+}
+
+// Merge returns a copy of conn, with all non-empty settings of other taking precedence
+func (conn Connection) Merge(other Connection) Connection {
+	if other.URL != "" {
+		conn.URL = other.URL
+	}
+	if other.Database != "" {
+		conn.Database = other.Database
+	}
+	if other.Port != 0 {
+		conn.Port = other.Port
+	}
+	if other.User != "" {
+		conn.User = other.User
+	}
+	if other.Password != "" {
+		conn.Password = other.Password
+	}
+	return conn
+}
+
+// placeholder matches a single ${NAME} placeholder
+var placeholder = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// Expand returns a copy of conn with all placeholders resolved using lookup, typically os.LookupEnv.
+// It is an error if lookup can not resolve a placeholder.
+func (conn Connection) Expand(lookup func(name string) (string, bool)) (Connection, error) {
+	var err error
+	expand := func(value string) string {
+		return placeholder.ReplaceAllStringFunc(value, func(match string) string {
+			name := placeholder.FindStringSubmatch(match)[1]
+			resolved, ok := lookup(name)
+			if !ok && err == nil {
+				err = fmt.Errorf("placeholder %q: variable %q is not set", match, name)
+			}
+			return resolved
+		})
+	}
+
+	conn.URL = expand(conn.URL)
+	conn.Database = expand(conn.Database)
+	conn.User = expand(conn.User)
+	conn.Password = expand(conn.Password)
+	return conn, err
+}
+
+// Apply sets the connection settings of server to conn
+func (conn Connection) Apply(server *Server) {
+	server.URL = conn.URL
+	server.Database = conn.Database
+	server.Port = conn.Port
+	server.User = conn.User
+	server.Password = conn.Password
+}
+
+// Connection returns the connection settings of server
+func (server Server) Connection() Connection {
+	return Connection{
+		URL:      server.URL,
+		Database: server.Database,
+		Port:     server.Port,
+		User:     server.User,
+		Password: server.Password,
+	}
+}
+
+// RegisterFlags registers flags setting each connection setting in conn with the given flag set.
+// Flags default to the empty value, so that the result can be passed to Merge.
+func (conn *Connection) RegisterFlags(flags *flag.FlagSet) {
+	flags.StringVar(&conn.URL, "db-url", conn.URL, "url of the database server to write into generated files, may contain ${ENV} placeholders")
+	flags.StringVar(&conn.Database, "db-name", conn.Database, "name of the database to write into generated files, may contain ${ENV} placeholders")
+	flags.IntVar(&conn.Port, "db-port", conn.Port, "port of the database server to write into generated files")
+	flags.StringVar(&conn.User, "db-user", conn.User, "database user to write into generated files, may contain ${ENV} placeholders")
+	flags.StringVar(&conn.Password, "db-password", conn.Password, "database password to write into generated files, may contain ${ENV} placeholders")
+}
+
+// RegisterDefaultFlags is like RegisterFlags, but registers flags for default settings, and no flag for the password.
+// It is intended for services generating files for others, which must never hand out a password of their own.
+func (conn *Connection) RegisterDefaultFlags(flags *flag.FlagSet) {
+	flags.StringVar(&conn.URL, "db-url", conn.URL, "default url of the database server to write into generated files, may contain ${ENV} placeholders")
+	flags.StringVar(&conn.Database, "db-name", conn.Database, "default name of the database to write into generated files, may contain ${ENV} placeholders")
+	flags.IntVar(&conn.Port, "db-port", conn.Port, "default port of the database server to write into generated files")
+	flags.StringVar(&conn.User, "db-user", conn.User, "default database user to write into generated files, may contain ${ENV} placeholders")
+}
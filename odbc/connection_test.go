@@ -0,0 +1,34 @@
+package odbc
+
+import "testing"
+
+func TestConnection_Expand(t *testing.T) {
+	env := map[string]string{"DB_USER": "wisski", "DB_HOST": "db"}
+	lookup := func(name string) (string, bool) {
+		value, ok := env[name]
+		return value, ok
+	}
+
+	tests := []struct {
+		name    string
+		conn    Connection
+		want    Connection
+		wantErr bool
+	}{
+		{"no placeholders", Connection{URL: "localhost", Port: 3306}, Connection{URL: "localhost", Port: 3306}, false},
+		{"placeholders", Connection{URL: "${DB_HOST}.example.com", User: "${DB_USER}"}, Connection{URL: "db.example.com", User: "wisski"}, false},
+		{"literal dollar", Connection{Password: "pa$$word"}, Connection{Password: "pa$$word"}, false},
+		{"unset variable", Connection{Password: "${DB_PASSWORD}"}, Connection{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.conn.Expand(lookup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Connection.Expand() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("Connection.Expand() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
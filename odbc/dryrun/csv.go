@@ -0,0 +1,81 @@
+package dryrun
+
+// cspell:words dbsql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/internal/sql"
+)
+
+var errEmptyCSV = errors.New("csv file has no header")
+
+// LoadCSV creates a table called name in db and inserts the records read from r.
+//
+// The first record of r is used as the column names, every column is created with type TEXT.
+// Empty values are inserted as NULL.
+func LoadCSV(ctx context.Context, db *dbsql.DB, name string, r io.Reader) error {
+	reader := csv.NewReader(r)
+
+	header, err := reader.Read()
+	if err == io.EOF {
+		return errEmptyCSV
+	}
+	if err != nil {
+		return err
+	}
+
+	columns := make([]string, len(header))
+	params := make([]string, len(header))
+	for i, column := range header {
+		columns[i] = sql.Identifier(column).Quoted()
+		params[i] = "?"
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	table := sql.Identifier(name).Quoted()
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("CREATE TABLE %s (%s TEXT)", table, strings.Join(columns, " TEXT, "))); err != nil {
+		return err
+	}
+
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", table, strings.Join(columns, ", "), strings.Join(params, ", ")))
+	if err != nil {
+		return err
+	}
+	defer insert.Close()
+
+	values := make([]interface{}, len(header))
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		for i, value := range record {
+			if value == "" {
+				values[i] = nil
+			} else {
+				values[i] = value
+			}
+		}
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,283 @@
+// Package dryrun executes the tables of an odbc file against a local database.
+//
+// It mimics what the WissKI odbc importer does, without writing anything to a triplestore.
+package dryrun
+
+// cspell:words odbc pathbuilder fieldname wisski dbsql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/internal/sql"
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Kind is the kind of an issue
+type Kind string
+
+const (
+	EmptyRequired Kind = "empty-required"  // a required field has no value
+	TooManyValues Kind = "too-many-values" // a field has more values than its cardinality allows
+)
+
+// Issue is a single issue found in an entity
+type Issue struct {
+	Kind   Kind   `json:"kind"`
+	Field  string `json:"field"`  // id of the field the issue was found in
+	Detail string `json:"detail"` // human-readable details
+}
+
+// Entity is a single entity that would be imported
+type Entity struct {
+	ID     string              `json:"id"`
+	Fields map[string][]string `json:"fields"`           // distinct values by field id, for display
+	Values map[string][]string `json:"-"`                // values by field id, including repetitions, as received by the importer
+	Issues []Issue             `json:"issues,omitempty"` // issues found in this entity
+}
+
+// Table is the result of executing a single table
+type Table struct {
+	Name     string   `json:"name"`
+	Bundle   string   `json:"bundle"`          // id of the main bundle
+	SQL      string   `json:"sql"`             // statement that was executed
+	Fields   []string `json:"fields"`          // ids of the fields, in document order
+	Entities []Entity `json:"entities"`        // entities in the order they were first returned
+	Error    string   `json:"error,omitempty"` // error executing the statement, if any
+}
+
+// Report is the result of a dry run
+type Report struct {
+	Tables []Table `json:"tables"`
+}
+
+// Issues returns the total number of issues and errors in this report
+func (r Report) Issues() (count int) {
+	for _, table := range r.Tables {
+		if table.Error != "" {
+			count++
+		}
+		for _, entity := range table.Entities {
+			count += len(entity.Issues)
+		}
+	}
+	return count
+}
+
+// Options determine which fields are checked during a dry run
+type Options struct {
+	Required    map[string]bool // field ids that must have at least one value
+	Cardinality map[string]int  // maximal number of values by field id, missing or non-positive means unlimited
+}
+
+// Cardinalities returns the cardinalities of all fields in the pathbuilder, as used in Options.
+func Cardinalities(pb pathbuilder.Pathbuilder) map[string]int {
+	cardinalities := make(map[string]int)
+	for _, bundle := range pb.Bundles() {
+		for _, field := range bundle.AllFields() {
+			if field.Cardinality > 0 {
+				cardinalities[field.Field] = field.Cardinality
+			}
+		}
+	}
+	return cardinalities
+}
+
+// Run executes every table of server against db.
+//
+// Errors executing individual tables are recorded in the report, and do not stop the run.
+// Only a cancelled context results in an error.
+func Run(ctx context.Context, db *dbsql.DB, server odbc.Server, opts Options) (r Report, err error) {
+	r.Tables = make([]Table, len(server.Tables))
+	for i, table := range server.Tables {
+		r.Tables[i] = runTable(ctx, db, table, opts)
+		if err := ctx.Err(); err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+// column is a field read from a column of the result set
+type column struct {
+	field string // id of the field
+	index int    // index of the column in the result
+}
+
+func runTable(ctx context.Context, db *dbsql.DB, table odbc.Table, opts Options) (result Table) {
+	result.Name = table.Name
+	result.Bundle = table.MainBundleID()
+	result.SQL = sql.SQLite.ForTable(table)
+
+	fields := tableFields(table.Row.BundlesAndFields, nil)
+	result.Fields = make([]string, len(fields))
+	for i, field := range fields {
+		result.Fields[i] = field.ID
+	}
+
+	rows, err := db.QueryContext(ctx, result.SQL)
+	if err != nil {
+		result.Error = queryError(result.SQL, err)
+		return
+	}
+	defer rows.Close()
+
+	names, err := rows.Columns()
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	// find the column of every field; the id is always the first column
+	indexes := make(map[string]int, len(names))
+	for i, name := range names {
+		if _, ok := indexes[name]; !ok {
+			indexes[name] = i
+		}
+	}
+	columns := make([]column, 0, len(fields))
+	for _, field := range fields {
+		name := field.FieldName
+		if name == "" {
+			name = field.ID
+		}
+		index, ok := indexes[name]
+		if !ok || index == 0 {
+			continue
+		}
+		columns = append(columns, column{field: field.ID, index: index})
+	}
+
+	values := make([]interface{}, len(names))
+	pointers := make([]interface{}, len(names))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	entities := make(map[string]int)
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			result.Error = err.Error()
+			return
+		}
+
+		id, ok := stringify(values[0])
+		if !ok {
+			continue
+		}
+
+		index, ok := entities[id]
+		if !ok {
+			index = len(result.Entities)
+			entities[id] = index
+			result.Entities = append(result.Entities, Entity{ID: id, Fields: make(map[string][]string), Values: make(map[string][]string)})
+		}
+		entity := &result.Entities[index]
+
+		for _, column := range columns {
+			value, ok := stringify(values[column.index])
+			if !ok {
+				continue
+			}
+			for _, part := range split(value, table.Delimiter, bool(table.Trim)) {
+				entity.Values[column.field] = append(entity.Values[column.field], part)
+				entity.Fields[column.field] = appendUnique(entity.Fields[column.field], part)
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		result.Error = err.Error()
+	}
+
+	for i := range result.Entities {
+		result.Entities[i].Issues = check(result.Entities[i], result.Fields, opts)
+	}
+	return
+}
+
+// tableFields appends all fields of bundles (including child bundles) to fields.
+func tableFields(bundles odbc.BundlesAndFields, fields []odbc.Field) []odbc.Field {
+	fields = append(fields, bundles.Fields...)
+	for _, bundle := range bundles.Bundles {
+		fields = tableFields(bundle.BundlesAndFields, fields)
+	}
+	return fields
+}
+
+// stringify turns a value returned from the database into a string.
+// NULL values return false.
+func stringify(value interface{}) (string, bool) {
+	switch value := value.(type) {
+	case nil:
+		return "", false
+	case []byte:
+		return string(value), true
+	case string:
+		return value, true
+	default:
+		return fmt.Sprint(value), true
+	}
+}
+
+// split splits value at delimiter and optionally trims each part, like the WissKI importer.
+// Empty parts are omitted.
+func split(value string, delimiter string, trim bool) (parts []string) {
+	candidates := []string{value}
+	if delimiter != "" {
+		candidates = strings.Split(value, delimiter)
+	}
+
+	for _, part := range candidates {
+		if trim {
+			part = strings.TrimSpace(part)
+		}
+		if part == "" {
+			continue
+		}
+		parts = append(parts, part)
+	}
+	return parts
+}
+
+// appendUnique appends value to values, unless it is already contained in it.
+//
+// Repeated values are only omitted for display; the importer receives every value, see Entity.Values.
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// check checks entity for issues.
+// Cardinalities are checked against the number of values including repetitions.
+func check(entity Entity, fields []string, opts Options) (issues []Issue) {
+	for _, field := range fields {
+		count := len(entity.Values[field])
+		if count == 0 && opts.Required[field] {
+			issues = append(issues, Issue{Kind: EmptyRequired, Field: field, Detail: "required field has no value"})
+		}
+		if limit := opts.Cardinality[field]; limit > 0 && count > limit {
+			issues = append(issues, Issue{Kind: TooManyValues, Field: field, Detail: fmt.Sprintf("field has %d values, but cardinality is %d", count, limit)})
+		}
+	}
+	return issues
+}
+
+// mariaDBSeparator matches the MariaDB-only SEPARATOR clause of GROUP_CONCAT, as generated by many2many selectors by default
+var mariaDBSeparator = regexp.MustCompile(`(?is)\bGROUP_CONCAT\s*\(.*?\bSEPARATOR\b`)
+
+// queryError returns the error message for executing statement.
+// If statement uses syntax only understood by MariaDB, the message says so.
+func queryError(statement string, err error) string {
+	if mariaDBSeparator.MatchString(statement) {
+		return fmt.Sprintf("%s (statement uses the MariaDB-only GROUP_CONCAT ... SEPARATOR syntax; generate the odbc using the sqlite dialect instead)", err)
+	}
+	return err.Error()
+}
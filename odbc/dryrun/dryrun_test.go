@@ -0,0 +1,150 @@
+package dryrun
+
+// cspell:words odbc dbsql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/xml"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/odbc"
+
+	_ "modernc.org/sqlite"
+)
+
+const dryrunServer = `<server>
+	<table>
+		<select>people.name AS name, nicknames.nick AS nick</select>
+		<name>people</name>
+		<append>LEFT JOIN nicknames ON nicknames.person = people.id</append>
+		<delimiter>;</delimiter>
+		<id>id</id>
+		<trim>TRUE</trim>
+		<row>
+			<bundle id="b_person">
+				<field id="f_name"><fieldname>name</fieldname></field>
+				<field id="f_nick"><fieldname>nick</fieldname></field>
+			</bundle>
+		</row>
+	</table>
+	<table>
+		<select>*</select>
+		<name>missing</name>
+		<id>id</id>
+		<row><bundle id="b_missing"></bundle></row>
+	</table>
+</server>`
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := dbsql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // every connection has its own in-memory database
+
+	if err := LoadCSV(ctx, db, "people", strings.NewReader("id,name\n1, Alice ; Al\n2,\n3,Carol\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := LoadCSV(ctx, db, "nicknames", strings.NewReader("person,nick\n1,Ali\n1,Lissy\n3,Caro\n3,Caro\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var server odbc.Server
+	if err := xml.Unmarshal([]byte(dryrunServer), &server); err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := Run(ctx, db, server, Options{
+		Required:    map[string]bool{"f_name": true},
+		Cardinality: map[string]int{"f_nick": 1},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(report.Tables) != 2 {
+		t.Fatalf("Run() returned %d tables, want 2", len(report.Tables))
+	}
+
+	wantEntities := []Entity{
+		{
+			ID: "1",
+			Fields: map[string][]string{
+				"f_name": {"Alice", "Al"},
+				"f_nick": {"Ali", "Lissy"},
+			},
+			// every row returned by the join repeats the name
+			Values: map[string][]string{
+				"f_name": {"Alice", "Al", "Alice", "Al"},
+				"f_nick": {"Ali", "Lissy"},
+			},
+			Issues: []Issue{{Kind: TooManyValues, Field: "f_nick", Detail: "field has 2 values, but cardinality is 1"}},
+		},
+		{
+			ID:     "2",
+			Fields: map[string][]string{},
+			Values: map[string][]string{},
+			Issues: []Issue{{Kind: EmptyRequired, Field: "f_name", Detail: "required field has no value"}},
+		},
+		{
+			// repeated values are shown once, but count towards the cardinality
+			ID: "3",
+			Fields: map[string][]string{
+				"f_name": {"Carol"},
+				"f_nick": {"Caro"},
+			},
+			Values: map[string][]string{
+				"f_name": {"Carol", "Carol"},
+				"f_nick": {"Caro", "Caro"},
+			},
+			Issues: []Issue{{Kind: TooManyValues, Field: "f_nick", Detail: "field has 2 values, but cardinality is 1"}},
+		},
+	}
+	if people := report.Tables[0]; people.Error != "" || !reflect.DeepEqual(people.Entities, wantEntities) {
+		t.Errorf("Run() people = %v (error %q), want %v", people.Entities, people.Error, wantEntities)
+	}
+
+	if missing := report.Tables[1]; missing.Error == "" {
+		t.Error("Run() missing: expected an error")
+	}
+
+	if got := report.Issues(); got != 4 {
+		t.Errorf("Report.Issues() = %d, want 4", got)
+	}
+}
+
+func TestRun_mariaDB(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := dbsql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1) // every connection has its own in-memory database
+
+	if err := LoadCSV(ctx, db, "people", strings.NewReader("id,name\n1,Alice\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	var server odbc.Server
+	server.Tables = []odbc.Table{{
+		Name:   "people",
+		ID:     "id",
+		Select: "GROUP_CONCAT(`people`.`name` SEPARATOR \";\") AS `names`",
+	}}
+
+	report, err := Run(ctx, db, server, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := report.Tables[0].Error; !strings.Contains(got, "MariaDB-only") {
+		t.Errorf("Run() error = %q, want mention of MariaDB-only syntax", got)
+	}
+}
@@ -0,0 +1,60 @@
+package dryrun
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// WriteText writes a human-readable table of every entity in this report to w.
+//
+// Every table is written as a tab-aligned table with one row per entity and one column per field.
+// Multiple values are separated by " | ", fields with issues are marked with a "!".
+func (r Report) WriteText(w io.Writer) error {
+	for _, table := range r.Tables {
+		if err := table.writeText(w); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%d issue(s) found\n", r.Issues())
+	return err
+}
+
+func (table Table) writeText(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "# %s (bundle %s, %d entities)\n", table.Name, table.Bundle, len(table.Entities)); err != nil {
+		return err
+	}
+	if table.Error != "" {
+		_, err := fmt.Fprintf(w, "error: %s\n\n", table.Error)
+		return err
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "id\t%s\n", strings.Join(table.Fields, "\t"))
+	for _, entity := range table.Entities {
+		marked := make(map[string]bool, len(entity.Issues))
+		for _, issue := range entity.Issues {
+			marked[issue.Field] = true
+		}
+
+		cells := make([]string, len(table.Fields))
+		for i, field := range table.Fields {
+			cells[i] = strings.Join(entity.Fields[field], " | ")
+			if marked[field] {
+				cells[i] = "!" + cells[i]
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\n", entity.ID, strings.Join(cells, "\t"))
+	}
+	for _, entity := range table.Entities {
+		for _, issue := range entity.Issues {
+			fmt.Fprintf(tw, "%s: %s: %s: %s\n", entity.ID, issue.Field, issue.Kind, issue.Detail)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,441 @@
+// Package migrate updates an existing odbc file to a new version of a pathbuilder
+package migrate
+
+// cspell:words odbc pathbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/internal/sql"
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Kind is the kind of a change
+type Kind string
+
+const (
+	Added   Kind = "added"   // a table, bundle or field was added using default selectors
+	Renamed Kind = "renamed" // the id of a bundle or field changed, and was followed by uuid
+	Moved   Kind = "moved"   // a bundle or field was moved into a different bundle
+	Deleted Kind = "deleted" // a table, bundle or field no longer exists, and was kept with DeletedComment
+	Removed Kind = "removed" // a table, bundle or field no longer exists, and was removed
+)
+
+// DeletedComment is the comment placed on tables, bundles and fields that no longer exist in the pathbuilder
+const DeletedComment = " DELETED: no longer exists in the pathbuilder "
+
+// Change is a single change made by Migrate
+type Change struct {
+	Kind   Kind   `json:"kind"`
+	Table  string `json:"table"`            // name of the table
+	Bundle string `json:"bundle,omitempty"` // id of the bundle, if any
+	Field  string `json:"field,omitempty"`  // id of the field, if any
+	Detail string `json:"detail"`           // human-readable details
+}
+
+func (change Change) String() string {
+	return fmt.Sprintf("%s: table %q: %s", change.Kind, change.Table, change.Detail)
+}
+
+// Options are options for Migrate
+type Options struct {
+	// Old is the pathbuilder the odbc file was created for, if known.
+	//
+	// It is used to follow renamed and moved paths by their uuid, and to tell paths that are new in the pathbuilder
+	// apart from paths deliberately left out of the odbc file.
+	// When nil, every enabled path missing from the odbc file is considered new.
+	Old *pathbuilder.Pathbuilder
+
+	// Remove removes tables, bundles and fields that no longer exist, instead of keeping them with DeletedComment.
+	Remove bool
+
+	// Dialect is the sql dialect to generate sql for new fields and tables in, nil means MariaDB.
+	Dialect *sql.Dialect
+}
+
+// Migrate updates server to match the new pathbuilder pb.
+//
+// Existing tables keep their select, append and delimiter statements.
+// New fields and bundles are added to the table of their main bundle; new main bundles are added as new tables.
+// Both use the default selectors, which select a column named after the machine name of each field.
+func Migrate(server odbc.Server, pb pathbuilder.Pathbuilder, opts Options) (odbc.Server, []Change, error) {
+	m := newMigrator(pb, opts)
+
+	tables := make([]odbc.Table, 0, len(server.Tables))
+	imported := make(map[string]struct{}, len(server.Tables))
+	for _, table := range server.Tables {
+		migrated, keep, err := m.migrateTable(table)
+		if err != nil {
+			return server, nil, err
+		}
+		if !keep {
+			continue
+		}
+		tables = append(tables, migrated)
+		imported[migrated.MainBundleID()] = struct{}{}
+	}
+
+	// add tables for new main bundles
+	fresh := odbc.NewServer(pb)
+	for _, bundle := range pb.Bundles() {
+		if _, ok := imported[bundle.Path.Bundle]; ok || !m.isNew(bundle.Path) {
+			continue
+		}
+
+		table := fresh.TableByID(bundle.Path.Bundle)
+		if err := sql.NewTableBuilder(*bundle).ApplyDialect(&table, opts.Dialect); err != nil {
+			return server, nil, err
+		}
+		tables = append(tables, table)
+		m.table = table.Name
+		m.report(Added, bundle.Path.Bundle, "", fmt.Sprintf("added table for bundle %q", bundle.Path.Bundle))
+	}
+
+	server.Tables = tables
+	return server, m.changes, nil
+}
+
+// fieldInBundle is a field along with the bundle it belongs to
+type fieldInBundle struct {
+	Field  pathbuilder.Field
+	Bundle *pathbuilder.Bundle
+}
+
+type migrator struct {
+	opts    Options
+	changes []Change
+
+	bundles       map[string]*pathbuilder.Bundle // new bundles by id
+	bundlesByUUID map[string]*pathbuilder.Bundle // new bundles by uuid
+	fields        map[string]fieldInBundle       // new fields by id
+	fieldsByUUID  map[string]fieldInBundle       // new fields by uuid
+
+	oldBundles map[string]pathbuilder.Path // old bundle paths by id
+	oldFields  map[string]pathbuilder.Path // old field paths by id
+	oldUUIDs   map[string]struct{}         // uuids of all old paths
+
+	// state of the current table
+	table     string
+	existing  map[string]existingField  // existing fields by new field id
+	existingB map[string]existingBundle // existing bundles by new bundle id
+	deleted   map[string][]odbc.Field   // deleted fields to keep, by new id of the enclosing bundle
+	deletedB  map[string][]odbc.Bundle  // deleted bundles to keep, by new id of the enclosing bundle
+	wanted    map[string]struct{}       // ids of new bundles containing existing bundles or fields
+	added     []pathbuilder.Field       // fields added to the current table
+}
+
+// existingField is a field found in the odbc file
+type existingField struct {
+	Field odbc.Field // with the id updated to the new pathbuilder
+	From  string     // new id of the enclosing bundle in the odbc file
+}
+
+// existingBundle is a bundle found in the odbc file
+type existingBundle struct {
+	Bundle odbc.Bundle
+	From   string // new id of the enclosing bundle in the odbc file
+}
+
+func newMigrator(pb pathbuilder.Pathbuilder, opts Options) *migrator {
+	m := &migrator{
+		opts: opts,
+
+		bundles:       make(map[string]*pathbuilder.Bundle),
+		bundlesByUUID: make(map[string]*pathbuilder.Bundle),
+		fields:        make(map[string]fieldInBundle),
+		fieldsByUUID:  make(map[string]fieldInBundle),
+
+		oldBundles: make(map[string]pathbuilder.Path),
+		oldFields:  make(map[string]pathbuilder.Path),
+		oldUUIDs:   make(map[string]struct{}),
+	}
+
+	var index func(bundle *pathbuilder.Bundle)
+	index = func(bundle *pathbuilder.Bundle) {
+		m.bundles[bundle.Path.Bundle] = bundle
+		if bundle.Path.UUID != "" {
+			m.bundlesByUUID[bundle.Path.UUID] = bundle
+		}
+		for _, field := range bundle.ChildFields {
+			m.fields[field.Field] = fieldInBundle{Field: field, Bundle: bundle}
+			if field.UUID != "" {
+				m.fieldsByUUID[field.UUID] = fieldInBundle{Field: field, Bundle: bundle}
+			}
+		}
+		for _, child := range bundle.ChildBundles {
+			index(child)
+		}
+	}
+	for _, bundle := range pb.Bundles() {
+		index(bundle)
+	}
+
+	if opts.Old != nil {
+		var indexOld func(bundle *pathbuilder.Bundle)
+		indexOld = func(bundle *pathbuilder.Bundle) {
+			m.oldBundles[bundle.Path.Bundle] = bundle.Path
+			m.oldUUIDs[bundle.Path.UUID] = struct{}{}
+			for _, field := range bundle.ChildFields {
+				m.oldFields[field.Field] = field.Path
+				m.oldUUIDs[field.UUID] = struct{}{}
+			}
+			for _, child := range bundle.ChildBundles {
+				indexOld(child)
+			}
+		}
+		for _, bundle := range opts.Old.Bundles() {
+			indexOld(bundle)
+		}
+		delete(m.oldUUIDs, "")
+	}
+
+	return m
+}
+
+// resolveBundle returns the new bundle corresponding to the bundle with the given id in the odbc file, or nil
+func (m *migrator) resolveBundle(id string) *pathbuilder.Bundle {
+	if old, ok := m.oldBundles[id]; ok && old.UUID != "" {
+		if bundle, ok := m.bundlesByUUID[old.UUID]; ok {
+			return bundle
+		}
+	}
+	return m.bundles[id]
+}
+
+// resolveField returns the new field corresponding to the field with the given id in the odbc file
+func (m *migrator) resolveField(id string) (fieldInBundle, bool) {
+	if old, ok := m.oldFields[id]; ok && old.UUID != "" {
+		if field, ok := m.fieldsByUUID[old.UUID]; ok {
+			return field, true
+		}
+	}
+	field, ok := m.fields[id]
+	return field, ok
+}
+
+// isNew checks if path is new in the pathbuilder, and should be added when missing from the odbc file
+func (m *migrator) isNew(path pathbuilder.Path) bool {
+	if !path.Enabled {
+		return false
+	}
+	if m.opts.Old == nil {
+		return true
+	}
+	if _, ok := m.oldUUIDs[path.UUID]; ok {
+		return false
+	}
+	if path.IsGroup {
+		_, ok := m.oldBundles[path.Bundle]
+		return !ok
+	}
+	_, ok := m.oldFields[path.Field]
+	return !ok
+}
+
+func (m *migrator) report(kind Kind, bundle, field, detail string) {
+	m.changes = append(m.changes, Change{
+		Kind:   kind,
+		Table:  m.table,
+		Bundle: bundle,
+		Field:  field,
+		Detail: detail,
+	})
+}
+
+// reportDeleted reports a deleted table, bundle or field.
+// It returns if the element should be kept.
+func (m *migrator) reportDeleted(bundle, field, detail string) (keep bool) {
+	if m.opts.Remove {
+		m.report(Removed, bundle, field, "removed "+detail)
+		return false
+	}
+	m.report(Deleted, bundle, field, "kept "+detail)
+	return true
+}
+
+// migrateTable migrates a single table.
+// keep indicates if the table should be kept.
+func (m *migrator) migrateTable(table odbc.Table) (_ odbc.Table, keep bool, err error) {
+	m.table = table.Name
+	m.existing = make(map[string]existingField)
+	m.existingB = make(map[string]existingBundle)
+	m.deleted = make(map[string][]odbc.Field)
+	m.deletedB = make(map[string][]odbc.Bundle)
+	m.wanted = make(map[string]struct{})
+	m.added = nil
+
+	if len(table.Row.Bundles) == 0 {
+		return table, true, nil
+	}
+
+	old := table.Row.Bundles[0]
+	main := m.resolveBundle(old.ID)
+	if main == nil {
+		if !m.reportDeleted(old.ID, "", fmt.Sprintf("table of deleted bundle %q", old.ID)) {
+			return table, false, nil
+		}
+		table.Row.Bundles = append([]odbc.Bundle{old}, table.Row.Bundles[1:]...)
+		table.Row.Bundles[0].Comment = DeletedComment
+		return table, true, nil
+	}
+
+	m.collect(old, main, "")
+
+	// copy the bundles, so that the caller's server is not modified
+	table.Row.Bundles = append([]odbc.Bundle{m.rebuild(main, "")}, table.Row.Bundles[1:]...)
+
+	// select the columns of added fields
+	if len(m.added) > 0 && strings.TrimSpace(table.Select) != "*" {
+		tb := sql.TableBuilder{
+			TableName: table.Name,
+			Fields:    make(map[string]sql.Selector, len(m.added)),
+		}
+		for _, field := range m.added {
+			tb.Fields[field.MachineName()] = &sql.ColumnSelector{Column: sql.Identifier(field.MachineName())}
+		}
+		columns, _, err := tb.BuildDialect(m.opts.Dialect)
+		if err != nil {
+			return table, false, err
+		}
+		if strings.TrimSpace(table.Select) == "" {
+			table.Select = columns
+		} else {
+			table.Select += ", " + columns
+		}
+	}
+
+	return table, true, nil
+}
+
+// contains checks if bundle is main or one of its descendants
+func contains(main, bundle *pathbuilder.Bundle) bool {
+	for ; bundle != nil; bundle = bundle.Parent {
+		if bundle == main {
+			return true
+		}
+	}
+	return false
+}
+
+// want marks bundle and its ancestors as wanted
+func (m *migrator) want(bundle *pathbuilder.Bundle) {
+	for ; bundle != nil; bundle = bundle.Parent {
+		m.wanted[bundle.Path.Bundle] = struct{}{}
+	}
+}
+
+// collect collects the existing and deleted contents of the odbc bundle b within the table of main.
+// live is the new id of the nearest enclosing bundle that still exists.
+//
+// If b no longer exists, returns the parts of it that should be kept, if any.
+func (m *migrator) collect(b odbc.Bundle, main *pathbuilder.Bundle, live string) (remainder *odbc.Bundle) {
+	bundle := m.resolveBundle(b.ID)
+	if bundle != nil && !contains(main, bundle) {
+		bundle = nil
+	}
+
+	key := live
+	if bundle != nil {
+		key = bundle.Path.Bundle
+		if key != b.ID {
+			m.report(Renamed, key, "", fmt.Sprintf("renamed bundle %q to %q", b.ID, key))
+		}
+		m.existingB[key] = existingBundle{Bundle: b, From: live}
+		m.want(bundle)
+	} else if m.reportDeleted(b.ID, "", fmt.Sprintf("deleted bundle %q", b.ID)) {
+		remainder = &odbc.Bundle{ID: b.ID, Comment: DeletedComment}
+	}
+
+	for _, field := range b.Fields {
+		resolved, ok := m.resolveField(field.ID)
+		if ok && contains(main, resolved.Bundle) {
+			id := resolved.Field.Field
+			if id != field.ID {
+				m.report(Renamed, resolved.Bundle.Path.Bundle, id, fmt.Sprintf("renamed field %q to %q", field.ID, id))
+			}
+			field.ID = id
+			m.existing[id] = existingField{Field: field, From: key}
+			m.want(resolved.Bundle)
+			continue
+		}
+
+		detail := fmt.Sprintf("deleted field %q", field.ID)
+		if ok {
+			detail = fmt.Sprintf("field %q, which moved to bundle %q outside of this table", field.ID, resolved.Bundle.Path.Bundle)
+		}
+		if !m.reportDeleted(key, field.ID, detail) {
+			continue
+		}
+
+		field.Comment = DeletedComment
+		if bundle != nil {
+			m.deleted[key] = append(m.deleted[key], field)
+		} else if remainder != nil {
+			remainder.Fields = append(remainder.Fields, field)
+		}
+	}
+
+	for _, child := range b.Bundles {
+		rest := m.collect(child, main, key)
+		switch {
+		case rest == nil:
+		case bundle != nil:
+			m.deletedB[key] = append(m.deletedB[key], *rest)
+		case remainder != nil:
+			remainder.Bundles = append(remainder.Bundles, *rest)
+		}
+	}
+
+	return remainder
+}
+
+// rebuild builds the odbc bundle for bundle from the collected contents.
+// parent is the id of the enclosing bundle.
+func (m *migrator) rebuild(bundle *pathbuilder.Bundle, parent string) (b odbc.Bundle) {
+	id := bundle.Path.Bundle
+	b.ID = id
+
+	if existing, ok := m.existingB[id]; ok {
+		b.Comment = existing.Bundle.Comment
+		if existing.From != parent {
+			m.report(Moved, id, "", fmt.Sprintf("moved bundle %q from %q to %q", id, existing.From, parent))
+		}
+	} else {
+		b.Comment = " " + bundle.Path.Name + " "
+		m.report(Added, id, "", fmt.Sprintf("added bundle %q", id))
+	}
+
+	for _, field := range bundle.Fields() {
+		if existing, ok := m.existing[field.Field]; ok {
+			if existing.From != id {
+				m.report(Moved, id, field.Field, fmt.Sprintf("moved field %q from %q to %q", field.Field, existing.From, id))
+			}
+			b.Fields = append(b.Fields, existing.Field)
+			continue
+		}
+		if !m.isNew(field.Path) {
+			continue
+		}
+
+		b.Fields = append(b.Fields, odbc.Field{
+			ID:        field.Field,
+			FieldName: field.MachineName(),
+			Comment:   " " + field.Name + " ",
+		})
+		m.added = append(m.added, field)
+		m.report(Added, id, field.Field, fmt.Sprintf("added field %q selecting column %q", field.Field, field.MachineName()))
+	}
+	b.Fields = append(b.Fields, m.deleted[id]...)
+
+	for _, child := range bundle.Bundles() {
+		if _, ok := m.wanted[child.Path.Bundle]; !ok && !m.isNew(child.Path) {
+			continue
+		}
+		b.Bundles = append(b.Bundles, m.rebuild(child, id))
+	}
+	b.Bundles = append(b.Bundles, m.deletedB[id]...)
+
+	return b
+}
@@ -0,0 +1,188 @@
+package migrate
+
+// cspell:words odbc pathbuilder
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+const oldPathbuilder = `<pathbuilderinterface>
+	<path><id>person</id><uuid>u-person</uuid><weight>0</weight><enabled>1</enabled><group_id>0</group_id><bundle>b_person</bundle><path_array><x>http://example.com/Person</x></path_array><datatype_property>empty</datatype_property><is_group>1</is_group><name>Person</name></path>
+	<path><id>name</id><uuid>u-name</uuid><weight>0</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_name</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/hasName</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Name</name></path>
+	<path><id>birth</id><uuid>u-birth</uuid><weight>1</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_birth</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/born</y><x>http://example.com/Date</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Birth</name></path>
+	<path><id>nickname</id><uuid>u-nickname</uuid><weight>2</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_nickname</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/nick</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Nickname</name></path>
+	<path><id>event</id><uuid>u-event</uuid><weight>3</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_event</bundle><path_array><x>http://example.com/Person</x><y>http://example.com/participated</y><x>http://example.com/Event</x></path_array><datatype_property>empty</datatype_property><is_group>1</is_group><name>Event</name></path>
+	<path><id>title</id><uuid>u-title</uuid><weight>0</weight><enabled>1</enabled><group_id>event</group_id><bundle>b_event</bundle><field>f_title</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/participated</y><x>http://example.com/Event</x><y>http://example.com/hasName</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Title</name></path>
+</pathbuilderinterface>`
+
+// newPathbuilder renames "name", deletes "birth", moves "title" into the person bundle and adds "gender" and "place".
+const newPathbuilder = `<pathbuilderinterface>
+	<path><id>person</id><uuid>u-person</uuid><weight>0</weight><enabled>1</enabled><group_id>0</group_id><bundle>b_person</bundle><path_array><x>http://example.com/Person</x></path_array><datatype_property>empty</datatype_property><is_group>1</is_group><name>Person</name></path>
+	<path><id>full_name</id><uuid>u-name</uuid><weight>0</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_full_name</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/hasName</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Full Name</name></path>
+	<path><id>nickname</id><uuid>u-nickname</uuid><weight>2</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_nickname</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/nick</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Nickname</name></path>
+	<path><id>gender</id><uuid>u-gender</uuid><weight>3</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_gender</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/gender</y><x>http://example.com/Gender</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Gender</name></path>
+	<path><id>title</id><uuid>u-title</uuid><weight>4</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_title</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/title</y><x>http://example.com/Title</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Title</name></path>
+	<path><id>event</id><uuid>u-event</uuid><weight>5</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_event</bundle><path_array><x>http://example.com/Person</x><y>http://example.com/participated</y><x>http://example.com/Event</x></path_array><datatype_property>empty</datatype_property><is_group>1</is_group><name>Event</name></path>
+	<path><id>place</id><uuid>u-place</uuid><weight>1</weight><enabled>1</enabled><group_id>0</group_id><bundle>b_place</bundle><path_array><x>http://example.com/Place</x></path_array><datatype_property>empty</datatype_property><is_group>1</is_group><name>Place</name></path>
+	<path><id>label</id><uuid>u-label</uuid><weight>0</weight><enabled>1</enabled><group_id>place</group_id><bundle>b_place</bundle><field>f_label</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Place</x><y>http://example.com/label</y><x>http://example.com/Label</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Label</name></path>
+</pathbuilderinterface>`
+
+// oldServer is a tuned odbc for oldPathbuilder, which leaves out the nickname
+const oldServer = `<server>
+	<url>localhost</url>
+	<table>
+		<select>` + "`people`.`full` as `name`, `people`.`born` as `birth`, `events`.`title` as `title`" + `</select>
+		<name>people</name>
+		<append>` + "LEFT JOIN `events` ON `people`.`id` = `events`.`person`" + `</append>
+		<delimiter>|</delimiter>
+		<id>id</id>
+		<row>
+			<bundle id="b_person">
+				<field id="f_name"><fieldname>name</fieldname></field>
+				<field id="f_birth"><fieldname>birth</fieldname></field>
+				<bundle id="b_event">
+					<field id="f_title"><fieldname>title</fieldname></field>
+				</bundle>
+			</bundle>
+		</row>
+	</table>
+</server>`
+
+func load(t *testing.T, data string) pathbuilder.Pathbuilder {
+	pb, err := pbxml.Unmarshal([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pb
+}
+
+func TestMigrate(t *testing.T) {
+	old := load(t, oldPathbuilder)
+	pb := load(t, newPathbuilder)
+
+	var server odbc.Server
+	if err := xml.Unmarshal([]byte(oldServer), &server); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		opts        Options
+		wantChanges []Change
+		wantFields  []odbc.Field // fields of the person bundle
+		wantSelect  string
+	}{
+		{
+			name: "with old pathbuilder",
+			opts: Options{Old: &old},
+			wantChanges: []Change{
+				{Kind: Renamed, Table: "people", Bundle: "b_person", Field: "f_full_name", Detail: `renamed field "f_name" to "f_full_name"`},
+				{Kind: Deleted, Table: "people", Bundle: "b_person", Field: "f_birth", Detail: `kept deleted field "f_birth"`},
+				{Kind: Added, Table: "people", Bundle: "b_person", Field: "f_gender", Detail: `added field "f_gender" selecting column "gender"`},
+				{Kind: Moved, Table: "people", Bundle: "b_person", Field: "f_title", Detail: `moved field "f_title" from "b_event" to "b_person"`},
+				{Kind: Added, Table: "place", Bundle: "b_place", Detail: `added table for bundle "b_place"`},
+			},
+			wantFields: []odbc.Field{
+				{ID: "f_full_name", FieldName: "name"},
+				{ID: "f_gender", FieldName: "gender", Comment: " Gender "},
+				{ID: "f_title", FieldName: "title"},
+				{ID: "f_birth", FieldName: "birth", Comment: DeletedComment},
+			},
+			wantSelect: "`people`.`full` as `name`, `people`.`born` as `birth`, `events`.`title` as `title`, `people`.`gender` as `gender`",
+		},
+		{
+			name: "without old pathbuilder",
+			opts: Options{Remove: true},
+			wantChanges: []Change{
+				{Kind: Removed, Table: "people", Bundle: "b_person", Field: "f_name", Detail: `removed deleted field "f_name"`},
+				{Kind: Removed, Table: "people", Bundle: "b_person", Field: "f_birth", Detail: `removed deleted field "f_birth"`},
+				{Kind: Added, Table: "people", Bundle: "b_person", Field: "f_full_name", Detail: `added field "f_full_name" selecting column "full_name"`},
+				{Kind: Added, Table: "people", Bundle: "b_person", Field: "f_nickname", Detail: `added field "f_nickname" selecting column "nickname"`},
+				{Kind: Added, Table: "people", Bundle: "b_person", Field: "f_gender", Detail: `added field "f_gender" selecting column "gender"`},
+				{Kind: Moved, Table: "people", Bundle: "b_person", Field: "f_title", Detail: `moved field "f_title" from "b_event" to "b_person"`},
+				{Kind: Added, Table: "place", Bundle: "b_place", Detail: `added table for bundle "b_place"`},
+			},
+			wantFields: []odbc.Field{
+				{ID: "f_full_name", FieldName: "full_name", Comment: " Full Name "},
+				{ID: "f_nickname", FieldName: "nickname", Comment: " Nickname "},
+				{ID: "f_gender", FieldName: "gender", Comment: " Gender "},
+				{ID: "f_title", FieldName: "title"},
+			},
+			wantSelect: "`people`.`full` as `name`, `people`.`born` as `birth`, `events`.`title` as `title`, `people`.`full_name` as `full_name`, `people`.`gender` as `gender`, `people`.`nickname` as `nickname`",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, changes, err := Migrate(server, pb, tt.opts)
+			if err != nil {
+				t.Fatalf("Migrate() error = %v", err)
+			}
+			if !reflect.DeepEqual(changes, tt.wantChanges) {
+				t.Errorf("Migrate() changes = %v, want %v", changes, tt.wantChanges)
+			}
+
+			if len(got.Tables) != 2 {
+				t.Fatalf("Migrate() returned %d tables, want 2", len(got.Tables))
+			}
+
+			people := got.Tables[0]
+			if people.Select != tt.wantSelect {
+				t.Errorf("Migrate() Select = %q, want %q", people.Select, tt.wantSelect)
+			}
+			if people.Append != server.Tables[0].Append || people.Delimiter != "|" {
+				t.Errorf("Migrate() did not preserve append and delimiter")
+			}
+
+			person := people.Row.Bundles[0]
+			if gotFields := stripNames(person.Fields); !reflect.DeepEqual(gotFields, tt.wantFields) {
+				t.Errorf("Migrate() Fields = %v, want %v", gotFields, tt.wantFields)
+			}
+			if len(person.Bundles) != 1 || person.Bundles[0].ID != "b_event" || len(person.Bundles[0].Fields) != 0 {
+				t.Errorf("Migrate() Bundles = %v, want only an empty event bundle", person.Bundles)
+			}
+
+			if got.Tables[1].MainBundleID() != "b_place" {
+				t.Errorf("Migrate() added table for %q, want %q", got.Tables[1].MainBundleID(), "b_place")
+			}
+		})
+	}
+}
+
+func TestMigrate_deletedTable(t *testing.T) {
+	pb := load(t, newPathbuilder)
+
+	server := odbc.Server{Tables: []odbc.Table{{Name: "things"}}}
+	server.Tables[0].Row.Bundles = []odbc.Bundle{{ID: "b_thing"}}
+
+	got, _, err := Migrate(server, pb, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tables) != 3 || got.Tables[0].Row.Bundles[0].Comment != DeletedComment {
+		t.Errorf("Migrate() did not keep deleted table")
+	}
+
+	got, _, err = Migrate(server, pb, Options{Remove: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tables) != 2 {
+		t.Errorf("Migrate() did not remove deleted table")
+	}
+}
+
+// stripNames removes xml names from fields, so that they can be compared
+func stripNames(fields []odbc.Field) []odbc.Field {
+	stripped := make([]odbc.Field, len(fields))
+	for i, field := range fields {
+		field.XMLName = xml.Name{}
+		stripped[i] = field
+	}
+	return stripped
+}
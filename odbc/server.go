@@ -26,11 +26,7 @@ type Server struct {
 
 // NewServer generates a new server from a pathbuilder
 func NewServer(pb pathbuilder.Pathbuilder) (s Server) {
-	s.URL = "localhost"
-	s.Database = ""
-	s.Port = 3306
-	s.User = ""
-	s.Password = ""
+	DefaultConnection().Apply(&s)
 
 	bundles := pb.Bundles()
 	s.Tables = make([]Table, len(bundles))
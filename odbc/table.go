@@ -82,8 +82,8 @@ func newField(field pathbuilder.Field) (f Field) {
 }
 
 type BundlesAndFields struct {
-	Fields  []Field
-	Bundles []Bundle
+	Fields  []Field  `xml:"field"`
+	Bundles []Bundle `xml:"bundle"`
 }
 
 func newBundlesAndFields(bundle pathbuilder.Bundle) (b BundlesAndFields) {
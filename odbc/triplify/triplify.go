@@ -0,0 +1,282 @@
+// Package triplify converts the rows selected by an odbc file directly into rdf.
+//
+// It emulates the WissKI odbc importer: every row becomes an entity of the table's bundle,
+// and every value of a field creates the path of that field in the pathbuilder.
+package triplify
+
+// cspell:words odbc pathbuilder wisski dbsql disamb
+
+import (
+	"context"
+	"crypto/sha256"
+	dbsql "database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/odbc/dryrun"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Defaults for Options
+const (
+	DefaultBase          = "urn:drincw:data:"
+	DefaultEntity        = "{base}{bundle}/{id}"
+	DefaultIntermediate  = "{entity}/{field}/{n}/{step}"
+	DefaultDisambiguated = "{base}disamb/{hash}"
+)
+
+// Options determine how uris are minted.
+//
+// Patterns may contain the following placeholders:
+//
+//   - {base}: the base uri
+//   - {bundle}: the id of the bundle of the table
+//   - {id}: the (escaped) id of the entity
+//   - {entity}: the uri of the entity
+//   - {field}: the id of the field (or child bundle) an intermediate node belongs to
+//   - {n}: the index of the value of the field
+//   - {step}: the index of the class of the node within the path
+//   - {hash}: a hash of the class and value of a disambiguated node
+//
+// Placeholders that make no sense for a pattern are replaced by the empty string.
+type Options struct {
+	Base string // base uri, defaults to DefaultBase
+
+	Entity        string // pattern for entities, defaults to DefaultEntity
+	Intermediate  string // pattern for intermediate nodes of a path, defaults to DefaultIntermediate
+	Disambiguated string // pattern for nodes identified by their value, defaults to DefaultDisambiguated
+}
+
+func (opts Options) withDefaults() Options {
+	if opts.Base == "" {
+		opts.Base = DefaultBase
+	}
+	if opts.Entity == "" {
+		opts.Entity = DefaultEntity
+	}
+	if opts.Intermediate == "" {
+		opts.Intermediate = DefaultIntermediate
+	}
+	if opts.Disambiguated == "" {
+		opts.Disambiguated = DefaultDisambiguated
+	}
+	return opts
+}
+
+// placeholders holds the values to replace placeholders with
+type placeholders struct {
+	bundle, id, entity, field string
+	n, step                   int
+	hash                      string
+}
+
+func (opts Options) mint(pattern string, p placeholders) rdf.Term {
+	return rdf.NewIRI(strings.NewReplacer(
+		"{base}", opts.Base,
+		"{bundle}", p.bundle,
+		"{id}", url.PathEscape(p.id),
+		"{entity}", p.entity,
+		"{field}", p.field,
+		"{n}", strconv.Itoa(p.n),
+		"{step}", strconv.Itoa(p.step),
+		"{hash}", p.hash,
+	).Replace(pattern))
+}
+
+// Convert executes every table of server against db and writes the triples WissKI would have created to w.
+//
+// Bundles and fields of the server are looked up in pb by their ids; those that do not exist are skipped.
+// Values are split and trimmed as described by each table.
+func Convert(ctx context.Context, db *dbsql.DB, server odbc.Server, pb pathbuilder.Pathbuilder, opts Options, w rdf.TripleWriter) error {
+	report, err := dryrun.Run(ctx, db, server, dryrun.Options{})
+	if err != nil {
+		return err
+	}
+
+	c := converter{
+		opts:    opts.withDefaults(),
+		w:       w,
+		bundles: make(map[string]*pathbuilder.Bundle),
+		fields:  make(map[string]fieldInBundle),
+		done:    make(map[string]struct{}),
+	}
+	for _, bundle := range pb.Bundles() {
+		c.index(bundle)
+	}
+
+	for _, table := range report.Tables {
+		if table.Error != "" {
+			return fmt.Errorf("table %q: %s", table.Name, table.Error)
+		}
+		bundle, ok := c.bundles[table.Bundle]
+		if !ok {
+			continue
+		}
+		for _, entity := range table.Entities {
+			if err := c.entity(bundle, table.Fields, entity); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type fieldInBundle struct {
+	field  pathbuilder.Field
+	bundle *pathbuilder.Bundle
+}
+
+type converter struct {
+	opts Options
+	w    rdf.TripleWriter
+
+	bundles map[string]*pathbuilder.Bundle // bundles by bundle id
+	fields  map[string]fieldInBundle       // fields by field id
+
+	shared map[string]rdf.Term // nodes of child bundles of the current entity, by path prefix
+	done   map[string]struct{} // disambiguated nodes with the remaining path already written
+}
+
+func (c *converter) index(bundle *pathbuilder.Bundle) {
+	c.bundles[bundle.Path.Bundle] = bundle
+	for _, field := range bundle.Fields() {
+		c.fields[field.Field] = fieldInBundle{field: field, bundle: bundle}
+	}
+	for _, child := range bundle.Bundles() {
+		c.index(child)
+	}
+}
+
+// entity writes the triples for a single entity of bundle
+func (c *converter) entity(bundle *pathbuilder.Bundle, fields []string, entity dryrun.Entity) error {
+	path := bundle.RelativePathArray(bundle)
+	if len(path) == 0 {
+		return nil
+	}
+
+	uri := c.opts.mint(c.opts.Entity, placeholders{bundle: bundle.Path.Bundle, id: entity.ID})
+	if err := c.write(uri, rdf.Type, rdf.NewIRI(path[0])); err != nil {
+		return err
+	}
+
+	c.shared = make(map[string]rdf.Term)
+	for _, id := range fields {
+		field, ok := c.fields[id]
+		if !ok || !isWithin(field.bundle, bundle) {
+			continue
+		}
+		// every value, including repetitions, creates a path; just like in WissKI
+		for n, value := range entity.Values[id] {
+			if err := c.value(bundle, uri, field, n, value); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// value writes the path of a single value of a field, starting at the entity uri.
+func (c *converter) value(bundle *pathbuilder.Bundle, uri rdf.Term, field fieldInBundle, n int, value string) error {
+	path := field.field.RelativePathArray(bundle)
+
+	// the index of the class at which the path is disambiguated, relative to path
+	disamb := -1
+	if field.field.Disamb > 0 {
+		disamb = 2*(field.field.Disamb-1) - (len(field.field.PathArray) - len(path))
+	}
+
+	// an entity reference is identified by the value at the last class
+	if field.field.Datatype() == "" {
+		disamb = len(path) - 1
+	}
+
+	node := uri
+	for step := 2; step < len(path); step += 2 {
+		predicate, class := path[step-1], path[step]
+
+		var next rdf.Term
+		var fresh bool
+		switch {
+		case step == disamb:
+			next = c.opts.mint(c.opts.Disambiguated, placeholders{hash: hash(class, value)})
+
+			// the remainder of the path is identical for every value, so write it only once
+			key := next.Value + "\x00" + strings.Join(path[step:], "\x00")
+			if _, ok := c.done[key]; ok {
+				return c.write(node, predicate, next)
+			}
+			c.done[key] = struct{}{}
+
+			// further nodes belong to the disambiguated node
+			uri, n = next, 0
+			fresh = true
+		default:
+			if owner := ownerOf(field.bundle, bundle, step); owner != nil {
+				key := strings.Join(path[:step+1], "\x00")
+				var ok bool
+				next, ok = c.shared[key]
+				if !ok {
+					next = c.opts.mint(c.opts.Intermediate, placeholders{bundle: bundle.Path.Bundle, entity: uri.Value, field: owner.Path.Bundle, step: step})
+					c.shared[key] = next
+					fresh = true
+				}
+				break
+			}
+			next = c.opts.mint(c.opts.Intermediate, placeholders{bundle: bundle.Path.Bundle, entity: uri.Value, field: field.field.Field, n: n, step: step})
+			fresh = true
+		}
+
+		if err := c.write(node, predicate, next); err != nil {
+			return err
+		}
+		if fresh {
+			if err := c.write(next, rdf.Type, rdf.NewIRI(class)); err != nil {
+				return err
+			}
+		}
+		node = next
+	}
+
+	datatype := field.field.Datatype()
+	if datatype == "" {
+		return nil
+	}
+	return c.write(node, datatype, rdf.NewLiteral(value, field.field.XSDType()))
+}
+
+func (c *converter) write(subject rdf.Term, predicate string, object rdf.Term) error {
+	return c.w.Write(rdf.Triple{Subject: subject, Predicate: rdf.NewIRI(predicate), Object: object})
+}
+
+// ownerOf returns the outermost bundle between bundle (exclusive) and root (exclusive)
+// whose own path contains the class at step (relative to root).
+// Such a node is shared by all fields of the child bundle.
+// If there is no such bundle, returns nil.
+func ownerOf(bundle, root *pathbuilder.Bundle, step int) (owner *pathbuilder.Bundle) {
+	for b := bundle; b != nil && b != root; b = b.Parent {
+		if step < len(b.RelativePathArray(root)) {
+			owner = b
+		}
+	}
+	return owner
+}
+
+// isWithin checks if bundle is root or one of its descendants
+func isWithin(bundle, root *pathbuilder.Bundle) bool {
+	for b := bundle; b != nil; b = b.Parent {
+		if b == root {
+			return true
+		}
+	}
+	return false
+}
+
+func hash(class, value string) string {
+	sum := sha256.Sum256([]byte(class + "\x00" + value))
+	return hex.EncodeToString(sum[:16])
+}
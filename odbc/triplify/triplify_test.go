@@ -0,0 +1,97 @@
+package triplify
+
+// cspell:words odbc pathbuilder dbsql
+
+import (
+	"context"
+	dbsql "database/sql"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/odbc"
+	"github.com/FAU-CDI/drincw/odbc/dryrun"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+
+	_ "modernc.org/sqlite"
+)
+
+const triplifyPathbuilder = `<pathbuilderinterface>
+	<path><id>person</id><weight>0</weight><enabled>1</enabled><group_id>0</group_id><bundle>b_person</bundle><path_array><x>http://example.com/Person</x></path_array><datatype_property>empty</datatype_property><is_group>1</is_group><name>Person</name></path>
+	<path><id>name</id><weight>0</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_name</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/hasName</y><x>http://example.com/Name</x></path_array><datatype_property>http://example.com/value</datatype_property><is_group>0</is_group><name>Name</name></path>
+	<path><id>place</id><weight>1</weight><enabled>1</enabled><group_id>person</group_id><bundle>b_person</bundle><field>f_place</field><fieldtype>string</fieldtype><path_array><x>http://example.com/Person</x><y>http://example.com/born</y><x>http://example.com/Place</x></path_array><datatype_property>http://example.com/label</datatype_property><disam>2</disam><is_group>0</is_group><name>Place</name></path>
+</pathbuilderinterface>`
+
+const triplifyServer = `<server>
+	<table>
+		<select>name, place</select>
+		<name>people</name>
+		<delimiter>;</delimiter>
+		<id>id</id>
+		<trim>TRUE</trim>
+		<row>
+			<bundle id="b_person">
+				<field id="f_name"><fieldname>name</fieldname></field>
+				<field id="f_place"><fieldname>place</fieldname></field>
+			</bundle>
+		</row>
+	</table>
+</server>`
+
+func TestConvert(t *testing.T) {
+	ctx := context.Background()
+
+	db, err := dbsql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(1)
+
+	if err := dryrun.LoadCSV(ctx, db, "people", strings.NewReader("id,name,place\n1,Alice; Al,Berlin\n2,Bob;Bob,Berlin\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	pb, err := pbxml.Unmarshal([]byte(triplifyPathbuilder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var server odbc.Server
+	if err := xml.Unmarshal([]byte(triplifyServer), &server); err != nil {
+		t.Fatal(err)
+	}
+
+	var builder strings.Builder
+	opts := Options{Base: "http://data.example.com/", Disambiguated: "{base}place/{hash}"}
+	if err := Convert(ctx, db, server, pb, opts, rdf.NewNTriplesWriter(&builder)); err != nil {
+		t.Fatal(err)
+	}
+
+	berlin := "<http://data.example.com/place/" + hash("http://example.com/Place", "Berlin") + ">"
+	want := strings.Join([]string{
+		`<http://data.example.com/b_person/1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Person> .`,
+		`<http://data.example.com/b_person/1> <http://example.com/hasName> <http://data.example.com/b_person/1/f_name/0/2> .`,
+		`<http://data.example.com/b_person/1/f_name/0/2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Name> .`,
+		`<http://data.example.com/b_person/1/f_name/0/2> <http://example.com/value> "Alice" .`,
+		`<http://data.example.com/b_person/1> <http://example.com/hasName> <http://data.example.com/b_person/1/f_name/1/2> .`,
+		`<http://data.example.com/b_person/1/f_name/1/2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Name> .`,
+		`<http://data.example.com/b_person/1/f_name/1/2> <http://example.com/value> "Al" .`,
+		`<http://data.example.com/b_person/1> <http://example.com/born> ` + berlin + ` .`,
+		berlin + ` <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Place> .`,
+		berlin + ` <http://example.com/label> "Berlin" .`,
+		`<http://data.example.com/b_person/2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Person> .`,
+		`<http://data.example.com/b_person/2> <http://example.com/hasName> <http://data.example.com/b_person/2/f_name/0/2> .`,
+		`<http://data.example.com/b_person/2/f_name/0/2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Name> .`,
+		`<http://data.example.com/b_person/2/f_name/0/2> <http://example.com/value> "Bob" .`,
+		`<http://data.example.com/b_person/2> <http://example.com/hasName> <http://data.example.com/b_person/2/f_name/1/2> .`,
+		`<http://data.example.com/b_person/2/f_name/1/2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Name> .`,
+		`<http://data.example.com/b_person/2/f_name/1/2> <http://example.com/value> "Bob" .`,
+		`<http://data.example.com/b_person/2> <http://example.com/born> ` + berlin + ` .`,
+		``,
+	}, "\n")
+
+	if got := builder.String(); got != want {
+		t.Errorf("Convert() =\n%s\nwant\n%s", got, want)
+	}
+}
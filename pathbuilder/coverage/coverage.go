@@ -0,0 +1,108 @@
+// Package coverage measures how many entities have values for each field of a pathbuilder.
+package coverage
+
+// cspell:words pathbuilder sparql
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/FAU-CDI/drincw/internal/endpoint"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/sparql"
+)
+
+// Report contains the coverage of a set of bundles
+type Report struct {
+	Bundles []Bundle // bundles (including child bundles) in pathbuilder order
+}
+
+// Bundle contains the coverage of fields within a single bundle
+type Bundle struct {
+	Bundle   *pathbuilder.Bundle
+	Entities int // total number of entities in the bundle
+
+	Fields []Field
+}
+
+// Field contains the coverage of a single field
+type Field struct {
+	Field pathbuilder.Field
+	Count int // number of entities with at least one value for this field
+}
+
+// Percent returns the percentage of entities with values for the given field.
+// If there are no entities, returns 0.
+func (b Bundle) Percent(field Field) float64 {
+	if b.Entities == 0 {
+		return 0
+	}
+	return 100 * float64(field.Count) / float64(b.Entities)
+}
+
+// Measure measures the coverage of the given bundles and their child bundles by sending COUNT queries to q.
+func Measure(ctx context.Context, q endpoint.Querier, bundles ...*pathbuilder.Bundle) (r Report, err error) {
+	for _, bundle := range bundles {
+		if bundle == nil || !bundle.Enabled {
+			continue
+		}
+		r.Bundles, err = measureBundle(ctx, q, r.Bundles, bundle)
+		if err != nil {
+			return r, err
+		}
+	}
+	return r, nil
+}
+
+func measureBundle(ctx context.Context, q endpoint.Querier, reports []Bundle, bundle *pathbuilder.Bundle) ([]Bundle, error) {
+	report := Bundle{Bundle: bundle}
+
+	var err error
+	report.Entities, err = count(ctx, q, sparql.CountEntities(bundle))
+	if err != nil {
+		return reports, fmt.Errorf("bundle %q: %w", bundle.MachineName(), err)
+	}
+
+	for _, field := range bundle.Fields() {
+		if !field.Enabled {
+			continue
+		}
+
+		fc := Field{Field: field}
+		fc.Count, err = count(ctx, q, sparql.CountField(bundle, field))
+		if err != nil {
+			return reports, fmt.Errorf("field %q: %w", field.MachineName(), err)
+		}
+		report.Fields = append(report.Fields, fc)
+	}
+	reports = append(reports, report)
+
+	for _, child := range bundle.Bundles() {
+		if !child.Enabled {
+			continue
+		}
+		reports, err = measureBundle(ctx, q, reports, child)
+		if err != nil {
+			return reports, err
+		}
+	}
+	return reports, nil
+}
+
+// count runs a count query and returns the result
+func count(ctx context.Context, q endpoint.Querier, query string) (int, error) {
+	results, err := q.Select(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	value, ok := results.Get(0, sparql.CountVariable)
+	if !ok {
+		return 0, nil
+	}
+	count, err := strconv.Atoi(value.Value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid count %q", value.Value)
+	}
+	return count, nil
+}
@@ -0,0 +1,75 @@
+package coverage
+
+// cspell:words pathbuilder sparql
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/internal/endpoint"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared by the tests of all pathbuilder packages
+var testPathbuilder = filepath.Join("..", "testdata", "pathbuilder.xml")
+
+// stubEndpoint returns a server answering count queries based on the properties they contain
+func stubEndpoint(t *testing.T, counts map[string]int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("stub endpoint: got method %s, want POST", r.Method)
+		}
+		query := r.FormValue("query")
+
+		count := counts[""]
+		for property, c := range counts {
+			if property != "" && strings.Contains(query, property) {
+				count = c
+			}
+		}
+
+		w.Header().Set("Content-Type", endpoint.MIMEResultsJSON)
+		fmt.Fprintf(w, `{"head":{"vars":["count"]},"results":{"bindings":[{"count":{"type":"literal","datatype":"http://www.w3.org/2001/XMLSchema#integer","value":"%d"}}]}}`, count)
+	}))
+}
+
+func TestMeasure(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := stubEndpoint(t, map[string]int{
+		"":                           4,
+		"<http://example.com/value>": 3,
+		"<http://example.com/alias>": 1,
+	})
+	defer server.Close()
+
+	report, err := Measure(context.Background(), endpoint.Endpoint{URL: server.URL}, pb.Bundles()...)
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+
+	var builder strings.Builder
+	if err := report.WriteCSV(&builder); err != nil {
+		t.Fatalf("WriteCSV() error = %v", err)
+	}
+
+	want := "bundle,bundle_name,field,field_name,entities,count,percent\n" +
+		"person,Person,name,Name,4,3,75.00\n" +
+		"person,Person,alias,Alias,4,1,25.00\n" +
+		"person,Person,birth_year,Birth Year,4,3,75.00\n" +
+		"person,Person,birth_place,Birth Place,4,4,100.00\n" +
+		"person,Person,knows,Knows,4,4,100.00\n" +
+		"event,Event,event_title,Title,4,3,75.00\n" +
+		"participants,Participants,participant_name,Participant Name,4,3,75.00\n"
+	if got := builder.String(); got != want {
+		t.Errorf("WriteCSV() = %q, want %q", got, want)
+	}
+}
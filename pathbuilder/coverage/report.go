@@ -0,0 +1,52 @@
+package coverage
+
+// cspell:words pathbuilder
+
+import (
+	_ "embed"
+	"encoding/csv"
+	"html/template"
+	"io"
+	"strconv"
+)
+
+// WriteCSV writes this report as csv into w.
+// Each row corresponds to a single field.
+func (r Report) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{"bundle", "bundle_name", "field", "field_name", "entities", "count", "percent"}); err != nil {
+		return err
+	}
+	for _, b := range r.Bundles {
+		for _, f := range b.Fields {
+			err := writer.Write([]string{
+				b.Bundle.MachineName(),
+				b.Bundle.Name,
+				f.Field.MachineName(),
+				f.Field.Name,
+				strconv.Itoa(b.Entities),
+				strconv.Itoa(f.Count),
+				strconv.FormatFloat(b.Percent(f), 'f', 2, 64),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+//go:embed report.html
+var reportHTML string
+
+var reportTemplate = template.Must(template.New("report.html").Funcs(template.FuncMap{
+	"percent": func(b Bundle, f Field) string {
+		return strconv.FormatFloat(b.Percent(f), 'f', 1, 64)
+	},
+}).Parse(reportHTML))
+
+// WriteHTML writes this report as a standalone html page into w.
+func (r Report) WriteHTML(w io.Writer) error {
+	return reportTemplate.Execute(w, r)
+}
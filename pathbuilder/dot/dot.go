@@ -4,193 +4,150 @@ package dot
 // cspell:words pathbuilder
 
 import (
-	"strings"
-
 	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/graph"
 	"github.com/emicklei/dot"
 )
 
-type Options struct {
-	Prefixes map[string]string // prefixes for urls to use
-
-	IDPrefix string // force id prefixes for specific nodes
-
-	FlatChildBundles        bool // do not create groups for each child bundle
-	IndependentChildBundles bool // consider each bundle a
-	CopyChildBundleNodes    bool // attempt to copy nodes which occur in unique child bundles
-
-	BundleUseDisplayNames bool   // use display names (as opposed to machine names) for bundle labels
-	ColorBundle           string // color to highlight starting points for bundles
-	ColorDatatype         string // color to use for datatype property nodes
-}
+// Options are options for the generated graph
+type Options = graph.Options
 
 // NewDot creates a new graph from the given pathbuilder
 func NewDot(pb pathbuilder.Pathbuilder, opts Options) *dot.Graph {
 	return NewDotForBundles(opts, pb.Bundles()...)
 }
 
-// NewDot creates a new graph from the given pathbuilder
+// NewDotForBundles creates a new graph from the given bundles
 func NewDotForBundles(opts Options, bundles ...*pathbuilder.Bundle) *dot.Graph {
-	g := dot.NewGraph(dot.Directed)
-
-	prefix := opts.IDPrefix
-	for _, bundle := range bundles {
-		if bundle == nil || !bundle.Enabled {
-			continue
-		}
-		opts.IDPrefix = prefix + ":::" + bundle.MachineName()
-		addBundle(NewBundleSubgraph(g, bundle, opts), bundle, opts, make(map[*dot.Graph]struct{}))
-	}
-
-	return g
+	return Render(graph.NewForBundles(opts, bundles...))
 }
 
-// AddBundle adds output for the given bundle to the given graph.
-func addBundle(g *dot.Graph, bundle *pathbuilder.Bundle, opts Options, gs map[*dot.Graph]struct{}) {
-	gs[g] = struct{}{} // add the current graph
+// Render renders the given graph into a dot graph
+func Render(g *graph.Graph) *dot.Graph {
+	// the zero theme has no templates, so rendering can not fail
+	d, _ := Theme{}.Render(g)
+	return d
+}
 
-	for _, field := range bundle.ChildFields {
-		addField(g, field, bundle, opts, gs)
+// Render renders the given graph into a dot graph using this theme
+func (theme Theme) Render(g *graph.Graph) (*dot.Graph, error) {
+	d := dot.NewGraph(dot.Directed)
+	if theme.RankDir != "" {
+		d.Attr("rankdir", theme.RankDir)
 	}
+	theme.font(d.AttributesMap)
 
-	for _, bundle := range bundle.ChildBundles {
-		addBundle(NewBundleSubgraph(g, bundle, opts), bundle, opts, gs)
+	r := renderer{
+		g:        g,
+		theme:    theme,
+		nodes:    make(map[*graph.Node]dot.Node, len(g.Nodes)),
+		clusters: make(map[*graph.Cluster]*dot.Graph),
+	}
+	if err := r.addNodes(d, &g.Cluster, nil); err != nil {
+		return nil, err
 	}
+	r.addEdges(&g.Cluster)
+	return d, nil
 }
 
-// NewBundleSubgraph adds a new subgraph for the given bundle.
-// It does not fill the subgraph.
-func NewBundleSubgraph(g *dot.Graph, bundle *pathbuilder.Bundle, opts Options) *dot.Graph {
-	if opts.FlatChildBundles && !bundle.IsToplevel() {
-		return g
+// font sets the font attributes of this theme, if any
+func (theme Theme) font(attrs dot.AttributesMap) {
+	if theme.Font != "" {
+		attrs.Attr("fontname", theme.Font)
 	}
-	b := g.Subgraph(bundle.MachineName(), dot.ClusterOption{})
-	if opts.BundleUseDisplayNames {
-		b.Label(bundle.Name)
-	} else {
-		b.Label(bundle.MachineName())
+	if theme.FontSize != 0 {
+		attrs.Attr("fontsize", theme.FontSize)
 	}
-	return b
 }
 
-func addField(g *dot.Graph, field pathbuilder.Field, bundle *pathbuilder.Bundle, opts Options, gs map[*dot.Graph]struct{}) {
-	var prev, now dot.Node
+type renderer struct {
+	g        *graph.Graph
+	theme    Theme
+	nodes    map[*graph.Node]dot.Node
+	clusters map[*graph.Cluster]*dot.Graph
+}
 
-	var maxParentID int
-	if bundle != nil {
-		maxParentID = len(bundle.PathArray)
-		if maxParentID%2 == 1 {
-			maxParentID--
+// addNodes adds the nodes and nested clusters of c to d.
+// parent is the parent of c, or nil if c is the graph itself.
+func (r renderer) addNodes(d *dot.Graph, c, parent *graph.Cluster) error {
+	r.clusters[c] = d
+
+	if parent == &r.g.Cluster && c.Bundle != nil {
+		if color, ok := r.theme.Bundles[c.Bundle.MachineName()]; ok {
+			d.Attr("color", color)
+			d.Attr("fontcolor", color)
 		}
 	}
 
-	var reuse bool // did we re-use an existing node in the parent?
-
-	for i := 0; i < len(field.PathArray); i += 2 {
-		gs := gs // list of graphs to search for existing edges in
-
-		prev = now // advance to the next node
+	for _, node := range c.Nodes {
+		label, err := r.theme.label(node)
+		if err != nil {
+			return err
+		}
 
-		// check if we can reuse an existing node
-		id := opts.NodeID(field.PathArray[i], bundle)
-		now, reuse = g.Root().FindNodeById(id)
+		n := d.Node(node.ID).Label(label)
+		r.theme.font(n.AttributesMap)
 
-		if opts.CopyChildBundleNodes {
-			reuse = reuse && i < maxParentID // only when we are not in the new bundle
+		style := r.theme.Nodes[node.Kind.String()]
+		if style.Shape != "" {
+			n.Attr("shape", style.Shape)
 		}
-
-		if !reuse {
-			label := opts.FormatID(field.PathArray[i])
-			now = g.Node(id).Label(label) // create a new node
-			gs = nil                      // force re-creating edges
+		if style.Style != "" {
+			n.Attr("style", style.Style)
 		}
 
-		// color the node if we didn't re-create it!
-		if !reuse && i == maxParentID {
-			now.Attr("fontcolor", opts.ColorBundle).Attr("color", opts.ColorBundle)
+		color := r.g.NodeColor(node)
+		if !r.g.Diff && style.Color != "" {
+			color = style.Color
 		}
-
-		if i == 0 {
-			continue
+		if color != "" {
+			n.Attr("fontcolor", color).Attr("color", color)
 		}
-
-		// do the edge insertion
-		rel := opts.FormatID(field.PathArray[i-1])
-		if anyHasEdge(g, gs, prev, now, rel) {
-			continue
+		if r.g.Dashed(node.Status) {
+			n.Attr("style", "dashed")
 		}
-		g.Edge(prev, now, rel)
-	}
-
-	// add a node for a datatype property
-	dp := opts.FormatID(field.Datatype())
-	if dp == "" || now.ID() == "" {
-		return
-	}
-
-	// create a node for the field
-
-	data := g.Node(opts.NodeID(field.MachineName(), bundle)).Label(field.Name)
-	edge := g.Edge(now, data, dp)
-	if opts.ColorDatatype != "" {
-		data.Attr("fontcolor", opts.ColorDatatype).Attr("color", opts.ColorDatatype)
-		edge.Attr("color", opts.ColorDatatype)
-	}
-}
-
-func anyHasEdge(g *dot.Graph, gs map[*dot.Graph]struct{}, from, to dot.Node, label string) bool {
-	if hasEdge(g, from, to, label) {
-		return true
-	}
-
-	for g := range gs {
-		if hasEdge(g, from, to, label) {
-			return true
+		if r.theme.Disamb && node.Disamb {
+			n.Attr("peripheries", 2)
 		}
+		r.nodes[node] = n
 	}
-	return false
-}
 
-// hasEdge checks if from and to have an edge between them
-func hasEdge(g *dot.Graph, from, to dot.Node, label string) bool {
-	for _, edge := range g.FindEdges(from, to) {
-		if edge.GetAttr("label") == label {
-			return true
+	for _, child := range c.Clusters {
+		if err := r.addNodes(d.Subgraph(child.ID, dot.ClusterOption{}).Label(child.Label), child, c); err != nil {
+			return err
 		}
 	}
-
-	return false
-}
-
-// NodeID returns the node id for a node with the given id inside the given bundle.
-func (opts Options) NodeID(id string, bundle *pathbuilder.Bundle) string {
-	id = opts.IDPrefix + ":::" + id
-	if opts.IndependentChildBundles && bundle != nil {
-		return bundle.MachineName() + ":::" + id
-	}
-	return opts.IDPrefix + ":::" + id
+	return nil
 }
 
-func (opts Options) FormatID(id string) string {
+// addEdges adds the edges of c and its nested clusters
+func (r renderer) addEdges(c *graph.Cluster) {
+	d := r.clusters[c]
+	for _, edge := range c.Edges {
+		e := d.Edge(r.nodes[edge.From], r.nodes[edge.To], edge.Label)
+		r.theme.font(e.AttributesMap)
 
-	// find the longest prefix that matches
-	var name, prefix string
-	for n, p := range opts.Prefixes {
-		if !strings.HasPrefix(id, p) {
-			continue
+		style := r.theme.edgeStyle(edge.URI, r.g.Options.Prefixes)
+		if style.Style != "" {
+			e.Attr("style", style.Style)
+		}
+		if style.PenWidth != 0 {
+			e.Attr("penwidth", style.PenWidth)
 		}
 
-		if len(p) > len(prefix) {
-			name = n
-			prefix = p
+		color := r.g.EdgeColor(edge)
+		if !r.g.Diff && style.Color != "" {
+			color = style.Color
+		}
+		if color != "" {
+			e.Attr("color", color)
+		}
+		if r.g.Dashed(edge.Status) {
+			e.Attr("style", "dashed")
 		}
 	}
 
-	// no prefix found
-	if name == "" {
-		return id
+	for _, child := range c.Clusters {
+		r.addEdges(child)
 	}
-
-	// apply the prefix
-	return name + ":" + strings.TrimPrefix(id, prefix)
 }
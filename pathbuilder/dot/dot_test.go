@@ -0,0 +1,58 @@
+package dot
+
+// cspell:words pathbuilder
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/pathbuilder/graph"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+func TestRender_golden(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range []struct {
+		name string
+		opts func(*Options)
+	}{
+		{"default", func(*Options) {}},
+		{"flat", func(opts *Options) { opts.FlatChildBundles = true }},
+		{"independent", func(opts *Options) { opts.IndependentChildBundles = true }},
+	} {
+		t.Run(mode.name, func(t *testing.T) {
+			opts := Options{
+				Prefixes:      map[string]string{"ex": "http://example.com/"},
+				ColorBundle:   "red",
+				ColorDatatype: "blue",
+			}
+			mode.opts(&opts)
+
+			got := []byte(Render(graph.New(pb, opts)).String())
+
+			golden := filepath.Join("testdata", mode.name+".dot")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("Render() does not match %s, got:\n%s", golden, got)
+			}
+		})
+	}
+}
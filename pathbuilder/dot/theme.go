@@ -0,0 +1,148 @@
+package dot
+
+// cspell:words pathbuilder rankdir fontname fontsize fontcolor penwidth peripheries jsonc
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/graph"
+	"muzzammil.xyz/jsonc"
+)
+
+// Theme controls the layout and appearance of a rendered dot graph.
+//
+// The zero theme renders a graph using only the colors from the graph options.
+type Theme struct {
+	RankDir  string  `json:"rankdir,omitempty"`  // direction of the layout, e.g. "TB" or "LR"
+	Font     string  `json:"font,omitempty"`     // font used for all labels
+	FontSize float64 `json:"fontsize,omitempty"` // font size used for all labels
+
+	// Nodes holds styles for each kind of node.
+	// Keys are the names of node kinds, i.e. "bundle", "class" and "datatype".
+	Nodes map[string]NodeStyle `json:"nodes,omitempty"`
+
+	// Bundles holds colors for the clusters of top-level bundles, by machine name.
+	Bundles map[string]string `json:"bundles,omitempty"`
+
+	// Edges holds styles for properties by namespace.
+	// Keys are either a prefix from the graph options or a full namespace uri; the longest matching namespace is used.
+	Edges map[string]EdgeStyle `json:"edges,omitempty"`
+
+	Cardinality bool `json:"cardinality,omitempty"` // append the cardinality of fields and bundles to their labels
+	Disamb      bool `json:"disamb,omitempty"`      // draw nodes at which fields are disambiguated with a double border
+
+	templates map[string]*template.Template // parsed label templates by node kind
+}
+
+// NodeStyle is the style of a kind of node
+type NodeStyle struct {
+	Shape string `json:"shape,omitempty"`
+	Color string `json:"color,omitempty"`
+	Style string `json:"style,omitempty"`
+
+	// Label is a text/template used to generate the label of a node.
+	// It is executed with a LabelData.
+	Label string `json:"label,omitempty"`
+}
+
+// EdgeStyle is the style of edges
+type EdgeStyle struct {
+	Color    string  `json:"color,omitempty"`
+	Style    string  `json:"style,omitempty"`
+	PenWidth float64 `json:"penwidth,omitempty"`
+}
+
+// LabelData is passed to label templates.
+//
+// Path holds the field (for datatype nodes) or the bundle (for bundle nodes) the node belongs to.
+// For other nodes, it is empty.
+type LabelData struct {
+	pathbuilder.Path
+
+	Label string // default label of the node
+	URI   string // uri of the class, if any
+	Kind  string // kind of node
+}
+
+// LoadTheme loads a theme in json format (with comments) from the given path
+func LoadTheme(path string) (theme Theme, err error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return theme, err
+	}
+	if err := jsonc.Unmarshal(bytes, &theme); err != nil {
+		return theme, err
+	}
+	return theme, theme.Compile()
+}
+
+// Compile parses the label templates of this theme.
+// It must be called after a theme has been modified and before it is used for rendering.
+func (theme *Theme) Compile() error {
+	theme.templates = make(map[string]*template.Template, len(theme.Nodes))
+	for kind, style := range theme.Nodes {
+		if style.Label == "" {
+			continue
+		}
+		tpl, err := template.New(kind).Parse(style.Label)
+		if err != nil {
+			return fmt.Errorf("label template for %q: %w", kind, err)
+		}
+		theme.templates[kind] = tpl
+	}
+	return nil
+}
+
+// label returns the label to use for the given node
+func (theme Theme) label(node *graph.Node) (string, error) {
+	data := LabelData{
+		Label: node.Label,
+		URI:   node.URI,
+		Kind:  node.Kind.String(),
+	}
+	if node.Path != nil {
+		data.Path = *node.Path
+	}
+
+	label := node.Label
+	if tpl, ok := theme.templates[data.Kind]; ok {
+		var buffer bytes.Buffer
+		if err := tpl.Execute(&buffer, data); err != nil {
+			return "", err
+		}
+		label = buffer.String()
+	}
+
+	if theme.Cardinality && node.Path != nil {
+		label += " [" + cardinality(node.Path.Cardinality) + "]"
+	}
+	return label, nil
+}
+
+// cardinality formats the given cardinality for display
+func cardinality(value int) string {
+	if value <= 0 {
+		return "*"
+	}
+	return strconv.Itoa(value)
+}
+
+// edgeStyle returns the style for an edge with the given property uri
+func (theme Theme) edgeStyle(uri string, prefixes map[string]string) (style EdgeStyle) {
+	var match string
+	for namespace, s := range theme.Edges {
+		if expanded, ok := prefixes[namespace]; ok {
+			namespace = expanded
+		}
+		if strings.HasPrefix(uri, namespace) && len(namespace) > len(match) {
+			match, style = namespace, s
+		}
+	}
+	return style
+}
@@ -0,0 +1,63 @@
+package dot
+
+// cspell:words pathbuilder rankdir fontname peripheries
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/pathbuilder/graph"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared by the tests of all pathbuilder packages
+var testPathbuilder = filepath.Join("..", "testdata", "pathbuilder.xml")
+
+func TestTheme_Render(t *testing.T) {
+	data, err := os.ReadFile(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// move the value property outside of any namespace
+	pb, err := pbxml.Unmarshal([]byte(strings.ReplaceAll(string(data), "http://example.com/value", "http://example.org/value")))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := LoadTheme(filepath.Join("testdata", "theme.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := graph.New(pb, Options{Prefixes: map[string]string{"ex": "http://example.com/"}})
+	d, err := theme.Render(g)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := d.String()
+
+	for _, want := range []string{
+		`rankdir="LR"`,
+		`fontname="Helvetica"`,
+		`color="orange"`,                                  // top-level bundle cluster
+		`label="ex:Person [*]",shape="box"`,               // bundle head with cardinality
+		`label="Name (string) [1]",shape="note"`,          // datatype label template
+		`label="ex:Name",peripheries="2",shape="ellipse"`, // disambiguation marker
+		`color="purple",fontname="Helvetica",label="ex:hasName",style="bold"`, // namespace edge style
+		`fontname="Helvetica",label="http://example.org/value"]`,              // edge outside of any namespace
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Theme.Render() does not contain %s, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestTheme_Compile(t *testing.T) {
+	theme := Theme{Nodes: map[string]NodeStyle{"datatype": {Label: "{{.Name"}}}
+	if err := theme.Compile(); err == nil {
+		t.Error("Theme.Compile() did not return an error for an invalid template")
+	}
+}
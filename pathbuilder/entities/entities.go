@@ -0,0 +1,125 @@
+// Package entities reassembles the results of bundle queries into nested entities.
+package entities
+
+// cspell:words pathbuilder sparql
+
+import (
+	"encoding/json"
+
+	"github.com/FAU-CDI/drincw/internal/endpoint"
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/sparql"
+)
+
+// Entity is a single entity of a bundle, along with the values of its fields and child bundles
+type Entity struct {
+	Bundle *pathbuilder.Bundle
+	URI    string
+
+	Fields  map[string][]rdf.Term // distinct values of each field, by machine name of the field
+	Bundles map[string][]*Entity  // entities of each child bundle, by machine name of the child bundle
+}
+
+// Assemble reassembles results of a query generated by sparql.NewBundleQuery into entities of the given bundle.
+//
+// Solutions are grouped by the entity variable of each bundle; entities and values are returned in the order they first occur.
+// Duplicate values (arising from the cross product of OPTIONAL blocks) are removed.
+func Assemble(results endpoint.Results, bundle *pathbuilder.Bundle) []*Entity {
+	return assemble(results.Bindings, sparql.NewLayout(bundle))
+}
+
+func assemble(rows []map[string]rdf.Term, layout *sparql.Layout) (entities []*Entity) {
+	index := make(map[rdf.Term]int)
+	groups := make([][]map[string]rdf.Term, 0)
+
+	for _, row := range rows {
+		term, ok := row[layout.Variable]
+		if !ok {
+			continue
+		}
+
+		i, ok := index[term]
+		if !ok {
+			i = len(entities)
+			index[term] = i
+
+			entities = append(entities, &Entity{
+				Bundle:  layout.Bundle,
+				URI:     term.Value,
+				Fields:  make(map[string][]rdf.Term, len(layout.Fields)),
+				Bundles: make(map[string][]*Entity, len(layout.Bundles)),
+			})
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], row)
+	}
+
+	for i, entity := range entities {
+		for _, field := range layout.Fields {
+			entity.Fields[field.Field.MachineName()] = values(groups[i], field.Variable)
+		}
+		for _, child := range layout.Bundles {
+			entity.Bundles[child.Bundle.MachineName()] = assemble(groups[i], child)
+		}
+	}
+
+	return entities
+}
+
+// values returns the distinct values of the given variable
+func values(rows []map[string]rdf.Term, variable string) (values []rdf.Term) {
+	seen := make(map[rdf.Term]struct{})
+	for _, row := range rows {
+		term, ok := row[variable]
+		if !ok {
+			continue
+		}
+		if _, ok := seen[term]; ok {
+			continue
+		}
+		seen[term] = struct{}{}
+		values = append(values, term)
+	}
+	return values
+}
+
+// MarshalJSON marshals this entity as a json object.
+//
+// Fields with a cardinality of 1 are represented by their single value (and omitted when they have none); all other fields are represented by an array of values.
+// Child bundles are always represented by an array of entities.
+func (entity *Entity) MarshalJSON() ([]byte, error) {
+	fields := make(map[string]interface{}, len(entity.Fields))
+	for name, terms := range entity.Fields {
+		values := make([]string, len(terms))
+		for i, term := range terms {
+			values[i] = term.Value
+		}
+
+		if entity.Bundle != nil && entity.Bundle.Field(name).Cardinality == 1 {
+			if len(values) > 0 {
+				fields[name] = values[0]
+			}
+			continue
+		}
+		fields[name] = values
+	}
+
+	bundles := make(map[string][]*Entity, len(entity.Bundles))
+	for name, children := range entity.Bundles {
+		if children == nil {
+			children = []*Entity{}
+		}
+		bundles[name] = children
+	}
+
+	return json.Marshal(struct {
+		URI     string                 `json:"uri"`
+		Fields  map[string]interface{} `json:"fields"`
+		Bundles map[string][]*Entity   `json:"bundles,omitempty"`
+	}{
+		URI:     entity.URI,
+		Fields:  fields,
+		Bundles: bundles,
+	})
+}
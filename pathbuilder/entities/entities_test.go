@@ -0,0 +1,70 @@
+package entities
+
+// cspell:words pathbuilder sparql
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/internal/endpoint"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared by the tests of all pathbuilder packages
+var testPathbuilder = filepath.Join("..", "testdata", "pathbuilder.xml")
+
+const testResultsXML = `<?xml version="1.0"?>
+<sparql xmlns="http://www.w3.org/2005/sparql-results#">
+	<head>
+		<variable name="b"/><variable name="b_f0"/><variable name="b_f1"/><variable name="b_f2"/><variable name="b_b0"/><variable name="b_b0_f0"/>
+	</head>
+	<results>
+		<result>
+			<binding name="b"><uri>http://example.com/alice</uri></binding>
+			<binding name="b_f0"><literal xml:lang="en">Alice</literal></binding>
+			<binding name="b_f1"><literal>Ally</literal></binding>
+			<binding name="b_f2"><literal datatype="http://www.w3.org/2001/XMLSchema#integer">1990</literal></binding>
+			<binding name="b_b0"><uri>http://example.com/alice/event</uri></binding>
+			<binding name="b_b0_f0"><literal>Conference</literal></binding>
+		</result>
+		<result>
+			<binding name="b"><uri>http://example.com/alice</uri></binding>
+			<binding name="b_f0"><literal xml:lang="en">Alice</literal></binding>
+			<binding name="b_f1"><literal>Al</literal></binding>
+			<binding name="b_f2"><literal datatype="http://www.w3.org/2001/XMLSchema#integer">1990</literal></binding>
+			<binding name="b_b0"><uri>http://example.com/alice/event</uri></binding>
+			<binding name="b_b0_f0"><literal>Conference</literal></binding>
+		</result>
+		<result>
+			<binding name="b"><uri>http://example.com/bob</uri></binding>
+		</result>
+	</results>
+</sparql>`
+
+func TestAssemble(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := endpoint.ParseResults(strings.NewReader(testResultsXML), "")
+	if err != nil {
+		t.Fatalf("ParseResults() error = %v", err)
+	}
+
+	entities := Assemble(results, pb.Bundle("person"))
+	got, err := json.Marshal(entities)
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+
+	want := `[` +
+		`{"uri":"http://example.com/alice","fields":{"alias":["Ally","Al"],"birth_year":"1990","knows":[],"name":"Alice"},"bundles":{"event":[{"uri":"http://example.com/alice/event","fields":{"event_title":"Conference"},"bundles":{"participants":[]}}]}},` +
+		`{"uri":"http://example.com/bob","fields":{"alias":[],"knows":[]},"bundles":{"event":[]}}` +
+		`]`
+	if string(got) != want {
+		t.Errorf("Assemble() = %s, want %s", got, want)
+	}
+}
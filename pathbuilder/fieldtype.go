@@ -0,0 +1,49 @@
+package pathbuilder
+
+// cspell:words pathbuilder xsd
+
+import "github.com/FAU-CDI/drincw/internal/rdf"
+
+// FieldTypeEntityReference is the field type of fields that reference other entities
+const FieldTypeEntityReference = "entity_reference"
+
+// fieldTypeXSD maps WissKI field types to xsd datatypes.
+// Field types not found in this map are treated as strings.
+var fieldTypeXSD = map[string]string{
+	"integer":      rdf.XSDNamespace + "integer",
+	"list_integer": rdf.XSDNamespace + "integer",
+	"timestamp":    rdf.XSDNamespace + "integer",
+
+	"decimal":    rdf.XSDNamespace + "decimal",
+	"float":      rdf.XSDNamespace + "double",
+	"list_float": rdf.XSDNamespace + "double",
+
+	"boolean": rdf.XSDNamespace + "boolean",
+
+	"datetime": rdf.XSDNamespace + "dateTime",
+
+	"link":  rdf.XSDNamespace + "anyURI",
+	"uri":   rdf.XSDNamespace + "anyURI",
+	"image": rdf.XSDNamespace + "anyURI",
+	"file":  rdf.XSDNamespace + "anyURI",
+
+	FieldTypeEntityReference: "",
+}
+
+// IsEntityReference checks if the path references other entities instead of holding literal values.
+func (p Path) IsEntityReference() bool {
+	return !p.IsGroup && p.FieldType == FieldTypeEntityReference
+}
+
+// XSDType returns the xsd datatype expected for literal values of this path.
+//
+// Returns the empty string for groups and for paths that reference other entities.
+func (p Path) XSDType() string {
+	if p.IsGroup {
+		return ""
+	}
+	if typ, ok := fieldTypeXSD[p.FieldType]; ok {
+		return typ
+	}
+	return rdf.XSDString
+}
@@ -0,0 +1,230 @@
+package graph
+
+// cspell:words pathbuilder
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// builder adds a single bundle, along with its child bundles, to a graph.
+//
+// Node identity is defined by path prefix: within a top-level bundle (or within each bundle when IndependentChildBundles is set), the same prefix of a path array always corresponds to the same node.
+// Nodes are placed into the cluster of the innermost bundle that contains all of their uses.
+// As such the resulting graph does not depend on the order of fields in the pathbuilder.
+type builder struct {
+	g    *Graph
+	opts Options
+	root *pathbuilder.Bundle // bundle being added, not necessarily a top-level bundle
+
+	clusters map[*pathbuilder.Bundle]*Cluster // cluster for each bundle
+
+	nodes map[string]*buildNode // nodes by id
+	edges map[string]*buildEdge // edges by id of their target node
+}
+
+// buildNode is a node that has not yet been placed into a cluster
+type buildNode struct {
+	*Node
+	owner *pathbuilder.Bundle // innermost bundle containing all uses of this node
+}
+
+// buildEdge is an edge that has not yet been placed into a cluster
+type buildEdge struct {
+	*Edge
+	to *buildNode
+}
+
+// addBundle adds output for the given bundle to the graph
+func (b *builder) addBundle(bundle *pathbuilder.Bundle) {
+	b.root = bundle
+	b.clusters = make(map[*pathbuilder.Bundle]*Cluster)
+	b.nodes = make(map[string]*buildNode)
+	b.edges = make(map[string]*buildEdge)
+
+	b.addClusters(&b.g.Cluster, bundle)
+	b.addPaths(bundle)
+	b.markHeads(bundle)
+	b.place()
+}
+
+// addClusters creates the clusters for the given bundle and its child bundles
+func (b *builder) addClusters(parent *Cluster, bundle *pathbuilder.Bundle) {
+	c := parent
+	if !b.opts.FlatChildBundles || bundle == b.root {
+		c = &Cluster{ID: bundle.MachineName(), Bundle: bundle}
+		if b.opts.BundleUseDisplayNames {
+			c.Label = bundle.Name
+		} else {
+			c.Label = bundle.MachineName()
+		}
+		parent.Clusters = append(parent.Clusters, c)
+	}
+	b.clusters[bundle] = c
+
+	for _, child := range bundle.Bundles() {
+		b.addClusters(c, child)
+	}
+}
+
+// addPaths creates nodes and edges for the fields of the given bundle and its child bundles
+func (b *builder) addPaths(bundle *pathbuilder.Bundle) {
+	for _, field := range bundle.ChildFields {
+		b.addField(field, bundle)
+	}
+	for _, child := range bundle.Bundles() {
+		b.addPaths(child)
+	}
+}
+
+func (b *builder) addField(field pathbuilder.Field, bundle *pathbuilder.Bundle) {
+	var prev, now *buildNode
+	for i := 0; i < len(field.PathArray); i += 2 {
+		prev = now
+
+		id := b.opts.NodeID(prefixKey(field.PathArray[:i+1]), bundle)
+		now = b.node(id, b.opts.FormatID(field.PathArray[i]), field.PathArray[i], b.owner(bundle, i))
+		now.use(bundle, field)
+		if field.Disamb > 0 && i == 2*(field.Disamb-1) {
+			now.Disamb = true
+		}
+
+		if i == 0 {
+			continue
+		}
+		b.edge(prev, now, field.PathArray[i-1]).use(bundle, field)
+	}
+
+	// add a node for a datatype property
+	if field.Datatype() == "" || now == nil {
+		return
+	}
+
+	id := now.ID + ":::" + field.MachineName()
+	data := b.node(id, field.Name, "", bundle)
+	data.Kind = DatatypeNode
+	data.Path = &field.Path
+	data.use(bundle, field)
+	b.edge(now, data, field.Datatype()).use(bundle, field)
+}
+
+// markHeads marks the nodes at which the given bundle and its child bundles start
+func (b *builder) markHeads(bundle *pathbuilder.Bundle) {
+	if head := len(bundle.PathArray) - 1; head >= 0 {
+		head -= head % 2
+		if node, ok := b.nodes[b.opts.NodeID(prefixKey(bundle.PathArray[:head+1]), bundle)]; ok && node.Kind == ClassNode {
+			node.Kind = BundleNode
+			node.Path = &bundle.Path
+		}
+	}
+	for _, child := range bundle.Bundles() {
+		b.markHeads(child)
+	}
+}
+
+// place adds all nodes and edges to the graph, in order of their ids
+func (b *builder) place() {
+	nodes := make([]*buildNode, 0, len(b.nodes))
+	for _, node := range b.nodes {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].ID < nodes[j].ID })
+
+	for _, node := range nodes {
+		node.Usage.sort()
+
+		c := b.clusters[node.owner]
+		b.g.index[node.ID] = node.Node
+		b.g.Nodes = append(b.g.Nodes, node.Node)
+		c.Nodes = append(c.Nodes, node.Node)
+	}
+
+	edges := make([]*buildEdge, 0, len(b.edges))
+	for _, edge := range b.edges {
+		edges = append(edges, edge)
+	}
+	sort.Slice(edges, func(i, j int) bool { return edges[i].To.ID < edges[j].To.ID })
+
+	for _, edge := range edges {
+		edge.Usage.sort()
+
+		c := b.clusters[edge.to.owner]
+		b.g.Edges = append(b.g.Edges, edge.Edge)
+		c.Edges = append(c.Edges, edge.Edge)
+	}
+}
+
+// node returns the node with the given id, creating it if it does not exist.
+// Owner is the bundle the current use of the node belongs to.
+func (b *builder) node(id, label, uri string, owner *pathbuilder.Bundle) *buildNode {
+	node, ok := b.nodes[id]
+	if !ok {
+		node = &buildNode{
+			Node:  &Node{ID: id, Label: label, URI: uri},
+			owner: owner,
+		}
+		b.nodes[id] = node
+		return node
+	}
+
+	node.owner = commonAncestor(node.owner, owner)
+	return node
+}
+
+// edge returns the edge from one node to another, creating it if it does not exist.
+// As node identity is defined by path prefix, each node has exactly one incoming edge.
+func (b *builder) edge(from, to *buildNode, uri string) *Edge {
+	edge, ok := b.edges[to.ID]
+	if !ok {
+		edge = &buildEdge{
+			Edge: &Edge{From: from.Node, To: to.Node, Label: b.opts.FormatID(uri), URI: uri},
+			to:   to,
+		}
+		b.edges[to.ID] = edge
+	}
+	return edge.Edge
+}
+
+// owner returns the bundle that the node at the given index of a path array of a field in bundle belongs to.
+// This is the outermost bundle (up to the bundle being added) whose own path array contains the node.
+func (b *builder) owner(bundle *pathbuilder.Bundle, index int) *pathbuilder.Bundle {
+	if b.opts.IndependentChildBundles {
+		return bundle
+	}
+
+	chain := make([]*pathbuilder.Bundle, 0)
+	for current := bundle; current != nil; current = current.Parent {
+		chain = append(chain, current)
+		if current == b.root {
+			break
+		}
+	}
+	for i := len(chain) - 1; i >= 0; i-- {
+		if index < len(chain[i].PathArray) {
+			return chain[i]
+		}
+	}
+	return bundle
+}
+
+// commonAncestor returns the innermost bundle that is equal to or an ancestor of both left and right
+func commonAncestor(left, right *pathbuilder.Bundle) *pathbuilder.Bundle {
+	ancestors := make(map[*pathbuilder.Bundle]struct{})
+	for current := left; current != nil; current = current.Parent {
+		ancestors[current] = struct{}{}
+	}
+	for current := right; current != nil; current = current.Parent {
+		if _, ok := ancestors[current]; ok {
+			return current
+		}
+	}
+	return left
+}
+
+// prefixKey returns a key identifying the given prefix of a path array
+func prefixKey(prefix []string) string {
+	// uris can not contain spaces, so this is unambiguous
+	return strings.Join(prefix, " ")
+}
@@ -0,0 +1,138 @@
+package graph
+
+// cspell:words pathbuilder
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+var update = flag.Bool("update", false, "update golden files in testdata")
+
+// goldenModes are the clustering modes tested against golden files
+var goldenModes = []struct {
+	name string
+	opts func(*Options)
+}{
+	{"default", func(*Options) {}},
+	{"flat", func(opts *Options) { opts.FlatChildBundles = true }},
+	{"independent", func(opts *Options) { opts.IndependentChildBundles = true }},
+}
+
+func TestNew_golden(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, mode := range goldenModes {
+		t.Run(mode.name, func(t *testing.T) {
+			opts := testOptions
+			mode.opts(&opts)
+
+			var buffer bytes.Buffer
+			if err := New(pb, opts).WriteMermaid(&buffer); err != nil {
+				t.Fatal(err)
+			}
+			got := buffer.Bytes()
+
+			golden := filepath.Join("testdata", mode.name+".mmd")
+			if *update {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("New() does not match %s, got:\n%s", golden, got)
+			}
+		})
+	}
+}
+
+func TestNew_fieldOrder(t *testing.T) {
+	for _, mode := range goldenModes {
+		t.Run(mode.name, func(t *testing.T) {
+			opts := testOptions
+			mode.opts(&opts)
+
+			pb, err := pbxml.Load(testPathbuilder)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want bytes.Buffer
+			if err := New(pb, opts).WriteMermaid(&want); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, bundle := range pb.Bundles() {
+				reverseFields(bundle)
+			}
+
+			var got bytes.Buffer
+			if err := New(pb, opts).WriteMermaid(&got); err != nil {
+				t.Fatal(err)
+			}
+
+			if got.String() != want.String() {
+				t.Errorf("New() depends on field order, got:\n%s\nwant:\n%s", got.String(), want.String())
+			}
+		})
+	}
+}
+
+func TestNewForBundles_childBundle(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	event := pb.FindBundle("event")
+	if event == nil {
+		t.Fatal("bundle 'event' not found")
+	}
+
+	for _, mode := range goldenModes {
+		t.Run(mode.name, func(t *testing.T) {
+			opts := testOptions
+			mode.opts(&opts)
+
+			g := NewForBundles(opts, event)
+			if len(g.Clusters) != 1 || g.Clusters[0].Bundle != event {
+				t.Fatalf("NewForBundles() did not create a single cluster for the bundle")
+			}
+
+			parents := g.parents()
+			for _, node := range g.Nodes {
+				if _, ok := parents[node]; !ok {
+					t.Errorf("NewForBundles() did not place node %q into a cluster", node.Label)
+				}
+			}
+		})
+	}
+}
+
+// reverseFields reverses the order of fields and child bundles in bundle and all its descendants
+func reverseFields(bundle *pathbuilder.Bundle) {
+	fields := bundle.ChildFields
+	for i, j := 0, len(fields)-1; i < j; i, j = i+1, j-1 {
+		fields[i], fields[j] = fields[j], fields[i]
+	}
+	bundles := bundle.ChildBundles
+	for i, j := 0, len(bundles)-1; i < j; i, j = i+1, j-1 {
+		bundles[i], bundles[j] = bundles[j], bundles[i]
+	}
+	for _, child := range bundle.ChildBundles {
+		reverseFields(child)
+	}
+}
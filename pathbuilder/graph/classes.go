@@ -0,0 +1,87 @@
+package graph
+
+// cspell:words pathbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// NewClassGraph creates a class-level graph from the given pathbuilder.
+// See NewClassGraphForBundles.
+func NewClassGraph(pb pathbuilder.Pathbuilder, opts Options) *Graph {
+	return NewClassGraphForBundles(opts, pb.Bundles()...)
+}
+
+// NewClassGraphForBundles aggregates the paths of the enabled fields in the given bundles and their child bundles into a single graph.
+//
+// The graph contains one node for each distinct class and one edge for each distinct property between two classes.
+// The usage of each edge records the bundles and fields making use of that step.
+// Edges are labeled with the property, the number of fields and the bundles they come from, e.g. "ecrm:P1_is_identified_by (3) [person, place]".
+// Classes at which a bundle starts are marked as bundle nodes.
+// The graph does not contain clusters or datatype nodes, and only the Prefixes and IDPrefix options are used.
+func NewClassGraphForBundles(opts Options, bundles ...*pathbuilder.Bundle) *Graph {
+	g := &Graph{
+		Options: opts,
+		index:   make(map[string]*Node),
+	}
+	edges := make(map[[3]string]*Edge)
+
+	class := func(uri string) *Node {
+		return g.addNode(&g.Cluster, opts.IDPrefix+uri, opts.FormatID(uri), uri)
+	}
+
+	var addBundle func(bundle *pathbuilder.Bundle)
+	addBundle = func(bundle *pathbuilder.Bundle) {
+		if bundle == nil || !bundle.Enabled {
+			return
+		}
+
+		if head := len(bundle.PathArray) - 1; head >= 0 {
+			class(bundle.PathArray[head-head%2]).Kind = BundleNode
+		}
+
+		for _, field := range bundle.Fields() {
+			if !field.Enabled {
+				continue
+			}
+
+			for i := 0; i < len(field.PathArray); i += 2 {
+				to := class(field.PathArray[i])
+				to.use(bundle, field)
+				if i == 0 {
+					continue
+				}
+
+				from := class(field.PathArray[i-2])
+				key := [3]string{from.ID, field.PathArray[i-1], to.ID}
+				edge, ok := edges[key]
+				if !ok {
+					edge = g.addEdge(&g.Cluster, from, to, opts.FormatID(field.PathArray[i-1]), field.PathArray[i-1])
+					edges[key] = edge
+				}
+				edge.use(bundle, field)
+			}
+		}
+
+		for _, child := range bundle.Bundles() {
+			addBundle(child)
+		}
+	}
+	for _, bundle := range bundles {
+		addBundle(bundle)
+	}
+
+	for _, edge := range g.Edges {
+		edge.Label = fmt.Sprintf("%s (%d) [%s]", edge.Label, edge.Weight(), strings.Join(edge.Bundles, ", "))
+	}
+
+	return g
+}
+
+// Weight returns the number of fields making use of this edge
+func (edge *Edge) Weight() int {
+	return len(edge.Fields)
+}
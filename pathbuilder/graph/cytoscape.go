@@ -0,0 +1,99 @@
+package graph
+
+// cspell:words cytoscape
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteCytoscape writes this graph in Cytoscape.js JSON format into w.
+//
+// Clusters are written as compound nodes with the kind "cluster"; nodes reference the innermost cluster they belong to using "parent".
+// For graphs created by Diff, nodes and edges additionally carry a "status".
+func (g *Graph) WriteCytoscape(w io.Writer) error {
+	ids := g.ids("n")
+	clusters := g.clusterIDs("c")
+	parents := g.parents()
+
+	var elements cytoscapeElements
+
+	status := func(status Status) string {
+		if !g.Diff {
+			return ""
+		}
+		return status.String()
+	}
+
+	g.Cluster.Walk(func(c, parent *Cluster) {
+		if parent == nil {
+			return
+		}
+		elements.Nodes = append(elements.Nodes, cytoscapeElement{Data: cytoscapeData{
+			ID:      clusters[c],
+			Label:   c.Label,
+			Kind:    "cluster",
+			Parent:  clusters[parent],
+			Cluster: c.ID,
+		}})
+	})
+
+	for _, node := range g.Nodes {
+		elements.Nodes = append(elements.Nodes, cytoscapeElement{Data: cytoscapeData{
+			ID:      ids[node],
+			Label:   node.Label,
+			Kind:    node.Kind.String(),
+			URI:     node.URI,
+			Parent:  clusters[parents[node]],
+			Bundles: node.Bundles,
+			Fields:  node.Fields,
+			Status:  status(node.Status),
+		}})
+	}
+
+	for i, edge := range g.Edges {
+		elements.Edges = append(elements.Edges, cytoscapeElement{Data: cytoscapeData{
+			ID:      fmt.Sprintf("e%d", i),
+			Source:  ids[edge.From],
+			Target:  ids[edge.To],
+			Label:   edge.Label,
+			URI:     edge.URI,
+			Bundles: edge.Bundles,
+			Fields:  edge.Fields,
+			Weight:  edge.Weight(),
+			Status:  status(edge.Status),
+		}})
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		Elements cytoscapeElements `json:"elements"`
+	}{Elements: elements})
+}
+
+type cytoscapeElements struct {
+	Nodes []cytoscapeElement `json:"nodes"`
+	Edges []cytoscapeElement `json:"edges"`
+}
+
+type cytoscapeElement struct {
+	Data cytoscapeData `json:"data"`
+}
+
+type cytoscapeData struct {
+	ID     string `json:"id"`
+	Source string `json:"source,omitempty"`
+	Target string `json:"target,omitempty"`
+	Parent string `json:"parent,omitempty"`
+
+	Label   string   `json:"label"`
+	Kind    string   `json:"kind,omitempty"`
+	URI     string   `json:"uri,omitempty"`
+	Cluster string   `json:"cluster,omitempty"`
+	Bundles []string `json:"bundles,omitempty"`
+	Fields  []string `json:"fields,omitempty"`
+	Weight  int      `json:"weight,omitempty"`
+	Status  string   `json:"status,omitempty"`
+}
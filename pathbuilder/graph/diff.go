@@ -0,0 +1,182 @@
+package graph
+
+// Status is the status of a node or edge in a graph created by Diff
+type Status int
+
+const (
+	Unchanged Status = iota // contained in both graphs
+	Removed                 // only contained in the old graph
+	Added                   // only contained in the new graph
+)
+
+// String returns a human-readable name of this status
+func (status Status) String() string {
+	switch status {
+	case Unchanged:
+		return "unchanged"
+	case Removed:
+		return "removed"
+	case Added:
+		return "added"
+	}
+	return "unknown"
+}
+
+// Colors used for nodes and edges in graphs created by Diff
+const (
+	ColorUnchanged = "grey"
+	ColorRemoved   = "red"
+	ColorAdded     = "green"
+)
+
+// Diff merges an old (before) and a new (after) graph into a single graph, marking each node and edge with its Status.
+//
+// Nodes are matched by id, edges by their endpoints and uri.
+// Nodes and clusters contained in both graphs are placed as in the new graph.
+// The options of the returned graph are the options of the new graph.
+func Diff(before, after *Graph) *Graph {
+	g := &Graph{
+		Options: after.Options,
+		Diff:    true,
+		index:   make(map[string]*Node),
+	}
+
+	d := differ{
+		g:      g,
+		edges:  make(map[edgeKey]*Edge),
+		before: edgeKeys(before),
+	}
+	d.merge(&g.Cluster, &after.Cluster, func(node *Node) Status {
+		if _, ok := before.index[node.ID]; ok {
+			return Unchanged
+		}
+		return Added
+	}, func(key edgeKey) Status {
+		if _, ok := d.before[key]; ok {
+			return Unchanged
+		}
+		return Added
+	})
+	d.merge(&g.Cluster, &before.Cluster, func(*Node) Status {
+		return Removed
+	}, func(edgeKey) Status {
+		return Removed
+	})
+
+	return g
+}
+
+// edgeKey uniquely identifies an edge across graphs
+type edgeKey struct {
+	from, uri, to string
+}
+
+func (edge *Edge) key() edgeKey {
+	return edgeKey{from: edge.From.ID, uri: edge.URI, to: edge.To.ID}
+}
+
+// edgeKeys returns the set of keys of edges in g
+func edgeKeys(g *Graph) map[edgeKey]struct{} {
+	keys := make(map[edgeKey]struct{}, len(g.Edges))
+	for _, edge := range g.Edges {
+		keys[edge.key()] = struct{}{}
+	}
+	return keys
+}
+
+type differ struct {
+	g *Graph
+
+	edges  map[edgeKey]*Edge    // edges already added to g
+	before map[edgeKey]struct{} // keys of edges in the old graph
+}
+
+// merge adds the nodes and edges of src that are not yet contained in the graph to dst, recursing into clusters.
+// nodeStatus and edgeStatus determine the status of newly added nodes and edges.
+func (d differ) merge(dst, src *Cluster, nodeStatus func(*Node) Status, edgeStatus func(edgeKey) Status) {
+	for _, node := range src.Nodes {
+		if _, ok := d.g.index[node.ID]; ok {
+			continue
+		}
+
+		n := d.g.addNode(dst, node.ID, node.Label, node.URI)
+		n.Kind = node.Kind
+		n.Path = node.Path
+		n.Disamb = node.Disamb
+		n.Usage = node.Usage
+		n.Status = nodeStatus(node)
+	}
+
+	for _, child := range src.Clusters {
+		var target *Cluster
+		for _, c := range dst.Clusters {
+			if c.ID == child.ID {
+				target = c
+				break
+			}
+		}
+		if target == nil {
+			target = &Cluster{ID: child.ID, Label: child.Label, Bundle: child.Bundle}
+			dst.Clusters = append(dst.Clusters, target)
+		}
+		d.merge(target, child, nodeStatus, edgeStatus)
+	}
+
+	for _, edge := range src.Edges {
+		key := edge.key()
+		if _, ok := d.edges[key]; ok {
+			continue
+		}
+
+		e := d.g.addEdge(dst, d.g.index[key.from], d.g.index[key.to], edge.Label, edge.URI)
+		e.Usage = edge.Usage
+		e.Status = edgeStatus(key)
+		d.edges[key] = e
+	}
+}
+
+// NodeColor returns the color to use for the given node, or the empty string.
+//
+// For graphs created by Diff, this is the color of the status of the node.
+// Otherwise it is the bundle or datatype color from the options.
+func (g *Graph) NodeColor(node *Node) string {
+	if g.Diff {
+		return statusColor(node.Status)
+	}
+	switch node.Kind {
+	case BundleNode:
+		return g.Options.ColorBundle
+	case DatatypeNode:
+		return g.Options.ColorDatatype
+	}
+	return ""
+}
+
+// EdgeColor returns the color to use for the given edge, or the empty string.
+//
+// For graphs created by Diff, this is the color of the status of the edge.
+// Otherwise edges leading to datatype nodes use the datatype color from the options.
+func (g *Graph) EdgeColor(edge *Edge) string {
+	if g.Diff {
+		return statusColor(edge.Status)
+	}
+	if edge.Datatype() {
+		return g.Options.ColorDatatype
+	}
+	return ""
+}
+
+// Dashed checks if the given node or edge status should be drawn dashed
+func (g *Graph) Dashed(status Status) bool {
+	return g.Diff && status == Removed
+}
+
+func statusColor(status Status) string {
+	switch status {
+	case Removed:
+		return ColorRemoved
+	case Added:
+		return ColorAdded
+	}
+	return ColorUnchanged
+}
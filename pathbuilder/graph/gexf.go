@@ -0,0 +1,129 @@
+package graph
+
+// cspell:words gexf defaultedgetype attvalues attvalue
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteGEXF writes this graph in GEXF 1.3 format into w.
+//
+// GEXF has no notion of clusters; instead each node has a "cluster" attribute holding the id of the innermost cluster it belongs to.
+// Nodes additionally carry the attributes "kind", "uri", "bundles" and "fields"; edges carry "uri", "bundles" and "fields", and are weighted by the number of fields using them.
+func (g *Graph) WriteGEXF(w io.Writer) error {
+	ids := g.ids("n")
+	parents := g.parents()
+
+	doc := gexfDocument{
+		XMLNS:   "http://gexf.net/1.3",
+		Version: "1.3",
+		Graph: gexfGraph{
+			DefaultEdgeType: "directed",
+			Mode:            "static",
+			Attributes: []gexfAttributes{
+				{
+					Class: "node",
+					Attributes: []gexfAttribute{
+						{ID: "kind", Title: "kind", Type: "string"},
+						{ID: "uri", Title: "uri", Type: "string"},
+						{ID: "bundles", Title: "bundles", Type: "string"},
+						{ID: "fields", Title: "fields", Type: "string"},
+						{ID: "cluster", Title: "cluster", Type: "string"},
+					},
+				},
+				{
+					Class: "edge",
+					Attributes: []gexfAttribute{
+						{ID: "uri", Title: "uri", Type: "string"},
+						{ID: "bundles", Title: "bundles", Type: "string"},
+						{ID: "fields", Title: "fields", Type: "string"},
+					},
+				},
+			},
+		},
+	}
+
+	for _, node := range g.Nodes {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, gexfElement{
+			ID:    ids[node],
+			Label: node.Label,
+			Values: []gexfValue{
+				{For: "kind", Value: node.Kind.String()},
+				{For: "uri", Value: node.URI},
+				{For: "bundles", Value: strings.Join(node.Bundles, ListSeparator)},
+				{For: "fields", Value: strings.Join(node.Fields, ListSeparator)},
+				{For: "cluster", Value: parents[node].ID},
+			},
+		})
+	}
+
+	for i, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, gexfElement{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: ids[edge.From],
+			Target: ids[edge.To],
+			Label:  edge.Label,
+			Weight: edge.Weight(),
+			Values: []gexfValue{
+				{For: "uri", Value: edge.URI},
+				{For: "bundles", Value: strings.Join(edge.Bundles, ListSeparator)},
+				{For: "fields", Value: strings.Join(edge.Fields, ListSeparator)},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type gexfDocument struct {
+	XMLName xml.Name  `xml:"gexf"`
+	XMLNS   string    `xml:"xmlns,attr"`
+	Version string    `xml:"version,attr"`
+	Graph   gexfGraph `xml:"graph"`
+}
+
+type gexfGraph struct {
+	DefaultEdgeType string           `xml:"defaultedgetype,attr"`
+	Mode            string           `xml:"mode,attr"`
+	Attributes      []gexfAttributes `xml:"attributes"`
+	Nodes           []gexfElement    `xml:"nodes>node"`
+	Edges           []gexfElement    `xml:"edges>edge"`
+}
+
+type gexfAttributes struct {
+	Class      string          `xml:"class,attr"`
+	Attributes []gexfAttribute `xml:"attribute"`
+}
+
+type gexfAttribute struct {
+	ID    string `xml:"id,attr"`
+	Title string `xml:"title,attr"`
+	Type  string `xml:"type,attr"`
+}
+
+// gexfElement is either a node or an edge
+type gexfElement struct {
+	ID     string      `xml:"id,attr"`
+	Source string      `xml:"source,attr,omitempty"`
+	Target string      `xml:"target,attr,omitempty"`
+	Label  string      `xml:"label,attr"`
+	Weight int         `xml:"weight,attr,omitempty"`
+	Values []gexfValue `xml:"attvalues>attvalue"`
+}
+
+type gexfValue struct {
+	For   string `xml:"for,attr"`
+	Value string `xml:"value,attr"`
+}
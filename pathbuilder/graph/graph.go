@@ -0,0 +1,264 @@
+// Package graph builds a format-neutral graph from the paths of a pathbuilder
+package graph
+
+// cspell:words pathbuilder
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Options determine how a graph is built and rendered
+type Options struct {
+	Prefixes map[string]string // prefixes for urls to use
+
+	IDPrefix string // force id prefixes for specific nodes
+
+	FlatChildBundles        bool // do not create groups for each child bundle
+	IndependentChildBundles bool // do not share nodes between bundles, drawing the paths of each bundle separately
+
+	BundleUseDisplayNames bool   // use display names (as opposed to machine names) for bundle labels
+	ColorBundle           string // color to highlight starting points for bundles
+	ColorDatatype         string // color to use for datatype property nodes
+}
+
+// Graph is a directed graph representing paths of a pathbuilder.
+//
+// Nodes represent classes and datatype properties, edges represent properties.
+// Nodes and edges are grouped into (possibly nested) clusters representing bundles.
+// The graph itself is the root cluster.
+type Graph struct {
+	Cluster
+	Options Options
+	Diff    bool // graph was created by Diff
+
+	Nodes []*Node // all nodes, in the order they were created
+	Edges []*Edge // all edges, in the order they were created
+
+	index map[string]*Node
+}
+
+// Cluster is a group of nodes and edges belonging to a bundle
+type Cluster struct {
+	ID     string
+	Label  string
+	Bundle *pathbuilder.Bundle // bundle represented by this cluster; nil for the graph itself
+
+	Nodes    []*Node    // nodes created within this cluster
+	Edges    []*Edge    // edges created within this cluster
+	Clusters []*Cluster // nested clusters
+}
+
+// NodeKind is the kind of a node
+type NodeKind int
+
+const (
+	ClassNode    NodeKind = iota // an intermediate class in a path
+	BundleNode                   // the class at which a bundle starts
+	DatatypeNode                 // the value of a datatype property
+)
+
+// String returns a human-readable name of this kind
+func (kind NodeKind) String() string {
+	switch kind {
+	case ClassNode:
+		return "class"
+	case BundleNode:
+		return "bundle"
+	case DatatypeNode:
+		return "datatype"
+	}
+	return "unknown"
+}
+
+// Node is a single node in the graph
+type Node struct {
+	ID    string // unique id of this node
+	Label string
+	URI   string // uri of the class, empty for datatype nodes
+	Kind  NodeKind
+
+	Path   *pathbuilder.Path // path of the field (for datatype nodes) or the bundle (for bundle nodes), if any
+	Disamb bool              // some field is disambiguated at this node
+
+	Usage
+	Status Status // only meaningful if the graph was created by Diff
+}
+
+// Edge is a single edge in the graph
+type Edge struct {
+	From, To *Node
+	Label    string
+	URI      string // uri of the property
+
+	Usage
+	Status Status // only meaningful if the graph was created by Diff
+}
+
+// Usage records which bundles and fields make use of a node or edge
+type Usage struct {
+	Bundles []string // machine names of bundles
+	Fields  []string // machine names of fields
+}
+
+// use records that the given field of the given bundle makes use of this node or edge
+func (usage *Usage) use(bundle *pathbuilder.Bundle, field pathbuilder.Field) {
+	if bundle != nil {
+		usage.Bundles = appendUnique(usage.Bundles, bundle.MachineName())
+	}
+	usage.Fields = appendUnique(usage.Fields, field.MachineName())
+}
+
+// sort sorts the bundles and fields of this usage
+func (usage *Usage) sort() {
+	sort.Strings(usage.Bundles)
+	sort.Strings(usage.Fields)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// Datatype checks if this edge leads to a datatype node
+func (edge *Edge) Datatype() bool {
+	return edge.To != nil && edge.To.Kind == DatatypeNode
+}
+
+// New creates a new graph from the given pathbuilder
+func New(pb pathbuilder.Pathbuilder, opts Options) *Graph {
+	return NewForBundles(opts, pb.Bundles()...)
+}
+
+// NewForBundles creates a new graph from the given bundles
+func NewForBundles(opts Options, bundles ...*pathbuilder.Bundle) *Graph {
+	g := &Graph{
+		Options: opts,
+		index:   make(map[string]*Node),
+	}
+
+	prefix := opts.IDPrefix
+	for _, bundle := range bundles {
+		if bundle == nil || !bundle.Enabled {
+			continue
+		}
+		b := builder{g: g, opts: opts}
+		b.opts.IDPrefix = prefix + ":::" + bundle.MachineName()
+		b.addBundle(bundle)
+	}
+
+	return g
+}
+
+// Node returns the node with the given id, if any
+func (g *Graph) Node(id string) (*Node, bool) {
+	node, ok := g.index[id]
+	return node, ok
+}
+
+// addNode adds a node to the given cluster, unless a node with the given id already exists.
+func (g *Graph) addNode(c *Cluster, id, label, uri string) *Node {
+	if node, ok := g.index[id]; ok {
+		return node
+	}
+
+	node := &Node{ID: id, Label: label, URI: uri}
+	g.index[id] = node
+	g.Nodes = append(g.Nodes, node)
+	c.Nodes = append(c.Nodes, node)
+	return node
+}
+
+// addEdge adds an edge to the given cluster
+func (g *Graph) addEdge(c *Cluster, from, to *Node, label, uri string) *Edge {
+	edge := &Edge{From: from, To: to, Label: label, URI: uri}
+	g.Edges = append(g.Edges, edge)
+	c.Edges = append(c.Edges, edge)
+	return edge
+}
+
+// Walk calls f for this cluster and each nested cluster in depth-first order, along with the parent of each cluster.
+// The parent of c itself is nil.
+func (c *Cluster) Walk(f func(c, parent *Cluster)) {
+	var walk func(c, parent *Cluster)
+	walk = func(c, parent *Cluster) {
+		f(c, parent)
+		for _, child := range c.Clusters {
+			walk(child, c)
+		}
+	}
+	walk(c, nil)
+}
+
+// ids assigns a short identifier to each node, consisting of the given prefix and the index of the node
+func (g *Graph) ids(prefix string) map[*Node]string {
+	ids := make(map[*Node]string, len(g.Nodes))
+	for i, node := range g.Nodes {
+		ids[node] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return ids
+}
+
+// clusterIDs assigns a short identifier to each nested cluster, consisting of the given prefix and the depth-first index of the cluster.
+// The graph itself is assigned the empty string.
+func (g *Graph) clusterIDs(prefix string) map[*Cluster]string {
+	ids := make(map[*Cluster]string)
+	g.Cluster.Walk(func(c, parent *Cluster) {
+		if parent == nil {
+			ids[c] = ""
+			return
+		}
+		ids[c] = fmt.Sprintf("%s%d", prefix, len(ids)-1)
+	})
+	return ids
+}
+
+// parents returns the cluster each node was created in
+func (g *Graph) parents() map[*Node]*Cluster {
+	parents := make(map[*Node]*Cluster, len(g.Nodes))
+	g.Cluster.Walk(func(c, parent *Cluster) {
+		for _, node := range c.Nodes {
+			parents[node] = c
+		}
+	})
+	return parents
+}
+
+// NodeID returns the node id for a node with the given id inside the given bundle.
+func (opts Options) NodeID(id string, bundle *pathbuilder.Bundle) string {
+	if opts.IndependentChildBundles && bundle != nil {
+		return opts.IDPrefix + ":::" + bundle.MachineName() + ":::" + id
+	}
+	return opts.IDPrefix + ":::" + id
+}
+
+func (opts Options) FormatID(id string) string {
+
+	// find the longest prefix that matches
+	var name, prefix string
+	for n, p := range opts.Prefixes {
+		if !strings.HasPrefix(id, p) {
+			continue
+		}
+
+		if len(p) > len(prefix) {
+			name = n
+			prefix = p
+		}
+	}
+
+	// no prefix found
+	if name == "" {
+		return id
+	}
+
+	// apply the prefix
+	return name + ":" + strings.TrimPrefix(id, prefix)
+}
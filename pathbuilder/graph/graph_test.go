@@ -0,0 +1,292 @@
+package graph
+
+// cspell:words pathbuilder
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared by the tests of all pathbuilder packages
+var testPathbuilder = filepath.Join("..", "testdata", "pathbuilder.xml")
+
+var testOptions = Options{
+	Prefixes:      map[string]string{"ex": "http://example.com/"},
+	ColorBundle:   "red",
+	ColorDatatype: "blue",
+}
+
+func ExampleGraph_WriteMermaid() {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		panic(err)
+	}
+
+	NewForBundles(testOptions, pb.FindBundle("event")).WriteMermaid(os.Stdout)
+
+	// Output: flowchart TD
+	//     subgraph c0 ["event"]
+	//         n0["ex:Person"]
+	//         n1["ex:Event"]
+	//         n2["ex:Name"]
+	//         n3["Title"]
+	//         subgraph c1 ["participants"]
+	//             n4["ex:Person"]
+	//             n5["ex:Name"]
+	//             n6["Participant Name"]
+	//         end
+	//     end
+	//     n0 -->|"ex:participated"| n1
+	//     n1 -->|"ex:hasName"| n2
+	//     n2 -->|"ex:value"| n3
+	//     linkStyle 2 stroke:blue
+	//     n1 -->|"ex:hasParticipant"| n4
+	//     n4 -->|"ex:hasName"| n5
+	//     n5 -->|"ex:value"| n6
+	//     linkStyle 5 stroke:blue
+	//     style n1 color:red,stroke:red
+	//     style n3 color:blue,stroke:blue
+	//     style n4 color:red,stroke:red
+	//     style n6 color:blue,stroke:blue
+}
+
+func ExampleGraph_WritePlantUML() {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		panic(err)
+	}
+
+	opts := testOptions
+	opts.FlatChildBundles = true
+	NewForBundles(opts, pb.FindBundle("event")).WritePlantUML(os.Stdout)
+
+	// Output: @startuml
+	// package "event" as c0 {
+	//     rectangle "ex:Person" as n0
+	//     rectangle "ex:Event" as n1 #line:red;text:red
+	//     rectangle "ex:Name" as n2
+	//     rectangle "Title" as n3 #line:blue;text:blue
+	//     rectangle "ex:Person" as n4 #line:red;text:red
+	//     rectangle "ex:Name" as n5
+	//     rectangle "Participant Name" as n6 #line:blue;text:blue
+	// }
+	// n0 --> n1 : ex:participated
+	// n1 --> n2 : ex:hasName
+	// n2 -[#blue]-> n3 : ex:value
+	// n1 --> n4 : ex:hasParticipant
+	// n4 --> n5 : ex:hasName
+	// n5 -[#blue]-> n6 : ex:value
+	// @enduml
+}
+
+func TestUsage(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(pb, testOptions)
+
+	tests := []struct {
+		label   string
+		bundles []string
+		fields  []string
+	}{
+		{"ex:Birth", []string{"person"}, []string{"birth_place", "birth_year"}},
+		{"ex:Event", []string{"event", "participants"}, []string{"event_title", "participant_name"}},
+		{"ex:Year", []string{"person"}, []string{"birth_year"}},
+		{"Title", []string{"event"}, []string{"event_title"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.label, func(t *testing.T) {
+			var node *Node
+			for _, n := range g.Nodes {
+				if n.Label == tt.label {
+					node = n
+				}
+			}
+			if node == nil {
+				t.Fatalf("node %q not found", tt.label)
+			}
+			if !reflect.DeepEqual(node.Bundles, tt.bundles) {
+				t.Errorf("Bundles = %v, want %v", node.Bundles, tt.bundles)
+			}
+			if !reflect.DeepEqual(node.Fields, tt.fields) {
+				t.Errorf("Fields = %v, want %v", node.Fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestGraph_Write(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(pb, testOptions)
+
+	tests := []struct {
+		name   string
+		write  func(w io.Writer) error
+		decode func(data []byte) error
+	}{
+		{"GraphML", g.WriteGraphML, wellFormedXML},
+		{"GEXF", g.WriteGEXF, wellFormedXML},
+		{"Cytoscape", g.WriteCytoscape, func(data []byte) error { return json.Unmarshal(data, new(interface{})) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buffer bytes.Buffer
+			if err := tt.write(&buffer); err != nil {
+				t.Fatalf("write error = %v", err)
+			}
+			if err := tt.decode(buffer.Bytes()); err != nil {
+				t.Errorf("output is not well-formed: %v", err)
+			}
+			for _, want := range []string{"http://example.com/Birth", "year"} {
+				if !bytes.Contains(buffer.Bytes(), []byte(want)) {
+					t.Errorf("output does not contain %q", want)
+				}
+			}
+		})
+	}
+}
+
+func TestGraph_WriteSVG(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := New(pb, testOptions)
+
+	var buffer bytes.Buffer
+	if err := g.WriteSVG(&buffer); err != nil {
+		t.Fatalf("WriteSVG() error = %v", err)
+	}
+	if err := wellFormedXML(buffer.Bytes()); err != nil {
+		t.Errorf("WriteSVG() output is not well-formed: %v", err)
+	}
+	for _, want := range []string{">ex:Birth</text>", ">event</text>", `stroke="red"`} {
+		if !strings.Contains(buffer.String(), want) {
+			t.Errorf("WriteSVG() output does not contain %q", want)
+		}
+	}
+
+	// no two nodes may overlap
+	l := newSVGLayout(g)
+	for i, left := range g.Nodes {
+		for _, right := range g.Nodes[i+1:] {
+			a, b := l.nodes[left], l.nodes[right]
+			if a.x < b.x+b.width && b.x < a.x+a.width && a.y < b.y+b.height && b.y < a.y+a.height {
+				t.Errorf("WriteSVG() nodes %q and %q overlap", left.Label, right.Label)
+			}
+		}
+	}
+}
+
+// wellFormedXML checks that data contains well-formed xml
+func wellFormedXML(data []byte) error {
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		_, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func ExampleNewClassGraph() {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		panic(err)
+	}
+
+	g := NewClassGraph(pb, testOptions)
+	for _, node := range g.Nodes {
+		fmt.Println(node.Label, node.Kind)
+	}
+	for _, edge := range g.Edges {
+		fmt.Println(edge.From.Label, "->", edge.To.Label, edge.Label)
+	}
+
+	// Output: ex:Person bundle
+	// ex:Name class
+	// ex:Birth class
+	// ex:Year class
+	// ex:Place class
+	// ex:Event bundle
+	// ex:Person -> ex:Name ex:hasName (3) [person, participants]
+	// ex:Person -> ex:Birth ex:born (2) [person]
+	// ex:Birth -> ex:Year ex:at (1) [person]
+	// ex:Birth -> ex:Place ex:in (1) [person]
+	// ex:Person -> ex:Person ex:knows (1) [person]
+	// ex:Person -> ex:Event ex:participated (2) [event, participants]
+	// ex:Event -> ex:Name ex:hasName (1) [event]
+	// ex:Event -> ex:Person ex:hasParticipant (1) [participants]
+}
+
+func TestDiff(t *testing.T) {
+	data, err := os.ReadFile(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	before, err := pbxml.Unmarshal(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// rename the 'at' property, and drop the 'name' field
+	changed := strings.ReplaceAll(string(data), "http://example.com/at", "http://example.com/in")
+	changed = strings.Replace(changed, "<id>name</id><weight>0</weight><enabled>1</enabled>", "<id>name</id><weight>0</weight><enabled>0</enabled>", 1)
+	after, err := pbxml.Unmarshal([]byte(changed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	g := Diff(New(before, testOptions), New(after, testOptions))
+
+	// unchanged nodes and edges are omitted
+	var got []string
+	for _, node := range g.Nodes {
+		if node.Status != Unchanged {
+			got = append(got, node.Label+": "+node.Status.String())
+		}
+	}
+	for _, edge := range g.Edges {
+		if edge.Status != Unchanged {
+			got = append(got, edge.From.Label+" "+edge.Label+" "+edge.To.Label+": "+edge.Status.String())
+		}
+	}
+	sort.Strings(got)
+
+	// nodes are identified by path prefix, so renaming 'at' also changes the nodes after it.
+	// the 'ex:Name' node is still used by the 'alias' field.
+	want := []string{
+		"Birth Year: added",
+		"Birth Year: removed",
+		"Name: removed",
+		"ex:Birth ex:at ex:Year: removed",
+		"ex:Birth ex:in ex:Year: added",
+		"ex:Name ex:value Name: removed",
+		"ex:Year ex:value Birth Year: added",
+		"ex:Year ex:value Birth Year: removed",
+		"ex:Year: added",
+		"ex:Year: removed",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Diff() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,136 @@
+package graph
+
+// cspell:words graphml edgedefault
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ListSeparator separates multiple values of a single attribute in GraphML and GEXF output
+const ListSeparator = " "
+
+// WriteGraphML writes this graph in GraphML format into w.
+//
+// Clusters are written as nested graphs, which tools like yEd display as groups.
+// Nodes carry the attributes "label", "kind", "uri", "bundles" and "fields"; edges carry "label", "uri", "bundles", "fields" and "weight".
+func (g *Graph) WriteGraphML(w io.Writer) error {
+	ids := g.ids("n")
+	clusters := g.clusterIDs("c")
+
+	doc := graphmlDocument{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphmlKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "kind", For: "node", Name: "kind", Type: "string"},
+			{ID: "uri", For: "node", Name: "uri", Type: "string"},
+			{ID: "bundles", For: "node", Name: "bundles", Type: "string"},
+			{ID: "fields", For: "node", Name: "fields", Type: "string"},
+			{ID: "cluster", For: "node", Name: "cluster", Type: "string"},
+			{ID: "elabel", For: "edge", Name: "label", Type: "string"},
+			{ID: "euri", For: "edge", Name: "uri", Type: "string"},
+			{ID: "ebundles", For: "edge", Name: "bundles", Type: "string"},
+			{ID: "efields", For: "edge", Name: "fields", Type: "string"},
+			{ID: "eweight", For: "edge", Name: "weight", Type: "int"},
+		},
+	}
+
+	var graph func(c *Cluster, id string) *graphmlGraph
+	graph = func(c *Cluster, id string) *graphmlGraph {
+		gg := &graphmlGraph{ID: id, EdgeDefault: "directed"}
+		for _, node := range c.Nodes {
+			gg.Nodes = append(gg.Nodes, graphmlNode{
+				ID: ids[node],
+				Data: []graphmlData{
+					{Key: "label", Value: node.Label},
+					{Key: "kind", Value: node.Kind.String()},
+					{Key: "uri", Value: node.URI},
+					{Key: "bundles", Value: strings.Join(node.Bundles, ListSeparator)},
+					{Key: "fields", Value: strings.Join(node.Fields, ListSeparator)},
+				},
+			})
+		}
+		for _, child := range c.Clusters {
+			gg.Nodes = append(gg.Nodes, graphmlNode{
+				ID: clusters[child],
+				Data: []graphmlData{
+					{Key: "label", Value: child.Label},
+					{Key: "kind", Value: "cluster"},
+					{Key: "cluster", Value: child.ID},
+				},
+				Graph: graph(child, clusters[child]+":"),
+			})
+		}
+		return gg
+	}
+	doc.Graph = graph(&g.Cluster, "G")
+
+	// edges may connect nodes in different clusters, so they are all declared on the top level
+	for i, edge := range g.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphmlEdge{
+			ID:     fmt.Sprintf("e%d", i),
+			Source: ids[edge.From],
+			Target: ids[edge.To],
+			Data: []graphmlData{
+				{Key: "elabel", Value: edge.Label},
+				{Key: "euri", Value: edge.URI},
+				{Key: "ebundles", Value: strings.Join(edge.Bundles, ListSeparator)},
+				{Key: "efields", Value: strings.Join(edge.Fields, ListSeparator)},
+				{Key: "eweight", Value: strconv.Itoa(edge.Weight())},
+			},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+type graphmlDocument struct {
+	XMLName xml.Name      `xml:"graphml"`
+	XMLNS   string        `xml:"xmlns,attr"`
+	Keys    []graphmlKey  `xml:"key"`
+	Graph   *graphmlGraph `xml:"graph"`
+}
+
+type graphmlKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphmlGraph struct {
+	ID          string        `xml:"id,attr"`
+	EdgeDefault string        `xml:"edgedefault,attr"`
+	Nodes       []graphmlNode `xml:"node"`
+	Edges       []graphmlEdge `xml:"edge"`
+}
+
+type graphmlNode struct {
+	ID    string        `xml:"id,attr"`
+	Data  []graphmlData `xml:"data"`
+	Graph *graphmlGraph `xml:"graph,omitempty"`
+}
+
+type graphmlEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphmlData `xml:"data"`
+}
+
+type graphmlData struct {
+	Key   string `xml:"key,attr"`
+	Value string `xml:",chardata"`
+}
@@ -0,0 +1,62 @@
+package graph
+
+// cspell:words linkstyle
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteMermaid writes this graph as a mermaid flowchart into w.
+//
+// Nodes are declared within (nested) subgraphs for their clusters; edges are declared afterwards.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	ids := g.ids("n")
+	clusters := g.clusterIDs("c")
+
+	var builder strings.Builder
+	builder.WriteString("flowchart TD\n")
+
+	var writeCluster func(c *Cluster, indent string)
+	writeCluster = func(c *Cluster, indent string) {
+		for _, node := range c.Nodes {
+			fmt.Fprintf(&builder, "%s%s[%s]\n", indent, ids[node], mermaidString(node.Label))
+		}
+		for _, child := range c.Clusters {
+			fmt.Fprintf(&builder, "%ssubgraph %s [%s]\n", indent, clusters[child], mermaidString(child.Label))
+			writeCluster(child, indent+"    ")
+			fmt.Fprintf(&builder, "%send\n", indent)
+		}
+	}
+	writeCluster(&g.Cluster, "    ")
+
+	for i, edge := range g.Edges {
+		fmt.Fprintf(&builder, "    %s -->|%s| %s\n", ids[edge.From], mermaidString(edge.Label), ids[edge.To])
+		if color := g.EdgeColor(edge); color != "" {
+			fmt.Fprintf(&builder, "    linkStyle %d stroke:%s%s\n", i, color, mermaidDash(g.Dashed(edge.Status)))
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if color := g.NodeColor(node); color != "" {
+			fmt.Fprintf(&builder, "    style %s color:%s,stroke:%s%s\n", ids[node], color, color, mermaidDash(g.Dashed(node.Status)))
+		}
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// mermaidString quotes a string for use as a mermaid label
+func mermaidString(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, "#quot;") + `"`
+}
+
+// mermaidDash returns the style to append for dashed nodes or edges
+func mermaidDash(dashed bool) string {
+	if !dashed {
+		return ""
+	}
+	return ",stroke-dasharray:5 5"
+}
@@ -0,0 +1,63 @@
+package graph
+
+// cspell:words plantuml startuml enduml
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WritePlantUML writes this graph as a PlantUML diagram into w.
+//
+// Nodes are rendered as rectangles, clusters as (nested) packages.
+func (g *Graph) WritePlantUML(w io.Writer) error {
+	ids := g.ids("n")
+	clusters := g.clusterIDs("c")
+
+	var builder strings.Builder
+	builder.WriteString("@startuml\n")
+
+	var writeCluster func(c *Cluster, indent string)
+	writeCluster = func(c *Cluster, indent string) {
+		for _, node := range c.Nodes {
+			fmt.Fprintf(&builder, "%srectangle %s as %s", indent, plantUMLString(node.Label), ids[node])
+			if color := g.NodeColor(node); color != "" {
+				dash := ""
+				if g.Dashed(node.Status) {
+					dash = ";line.dashed"
+				}
+				fmt.Fprintf(&builder, " #line:%s;text:%s%s", color, color, dash)
+			}
+			builder.WriteString("\n")
+		}
+		for _, child := range c.Clusters {
+			fmt.Fprintf(&builder, "%spackage %s as %s {\n", indent, plantUMLString(child.Label), clusters[child])
+			writeCluster(child, indent+"    ")
+			fmt.Fprintf(&builder, "%s}\n", indent)
+		}
+	}
+	writeCluster(&g.Cluster, "")
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if color := g.EdgeColor(edge); color != "" {
+			style := "#" + color
+			if g.Dashed(edge.Status) {
+				style += ",dashed"
+			}
+			arrow = "-[" + style + "]->"
+		}
+		fmt.Fprintf(&builder, "%s %s %s : %s\n", ids[edge.From], arrow, ids[edge.To], edge.Label)
+	}
+
+	builder.WriteString("@enduml\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// plantUMLString quotes a string for use as a PlantUML label
+func plantUMLString(value string) string {
+	return `"` + strings.ReplaceAll(value, `"`, "'") + `"`
+}
@@ -0,0 +1,283 @@
+package graph
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// Dimensions used to lay out svg output, in pixels
+const (
+	svgCharWidth   = 7  // approximate width of a single character of a label
+	svgNodeHeight  = 24 // height of a node
+	svgRowHeight   = 40 // vertical distance between two rows of nodes
+	svgColumnGap   = 80 // horizontal distance between two columns of nodes
+	svgClusterPad  = 10 // padding between a cluster and its contents
+	svgClusterHead = 16 // additional space above a cluster for its label
+	svgArrowSize   = 8  // length of an arrow head
+)
+
+// WriteSVG writes this graph as a standalone svg image into w.
+//
+// Unlike the other formats, svg output is laid out without any external tools.
+// Nodes are placed from left to right in columns by their distance from a node without incoming edges.
+// As each node of a graph created by New has at most one incoming edge, this draws the paths of a bundle as a tree.
+// Clusters are drawn as boxes around the nodes they contain.
+func (g *Graph) WriteSVG(w io.Writer) error {
+	l := newSVGLayout(g)
+
+	var builder strings.Builder
+	fmt.Fprintf(&builder, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", l.width, l.height, l.width, l.height)
+
+	// clusters, outermost first
+	g.Cluster.Walk(func(c, parent *Cluster) {
+		box, ok := l.clusters[c]
+		if parent == nil || !ok {
+			return
+		}
+		fmt.Fprintf(&builder, `<g class="cluster"><rect x="%d" y="%d" width="%d" height="%d" rx="4" fill="none" stroke="black"/><text x="%d" y="%d">%s</text></g>`+"\n", box.x, box.y, box.width, box.height, box.x+4, box.y+12, svgEscape(c.Label))
+	})
+
+	for _, edge := range g.Edges {
+		from, to := l.nodes[edge.From], l.nodes[edge.To]
+		x1, y1 := from.x+from.width, from.y+svgNodeHeight/2
+		x2, y2 := to.x, to.y+svgNodeHeight/2
+		if to.x <= from.x {
+			// edges going backwards connect the left side of both nodes
+			x1 = from.x
+		}
+
+		stroke := svgColor(g.EdgeColor(edge))
+		fmt.Fprintf(&builder, `<g class="edge"><line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s"%s/><polygon points="%s" fill="%s"/><text x="%d" y="%d" text-anchor="middle" fill="%s">%s</text></g>`+"\n", x1, y1, x2, y2, stroke, svgDash(g.Dashed(edge.Status)), svgArrow(x1, y1, x2, y2), stroke, (x1+x2)/2, (y1+y2)/2-4, stroke, svgEscape(edge.Label))
+	}
+
+	for _, node := range g.Nodes {
+		box := l.nodes[node]
+		color := svgColor(g.NodeColor(node))
+		fmt.Fprintf(&builder, `<g class="node %s"><rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="white" stroke="%s"%s/><text x="%d" y="%d" text-anchor="middle" fill="%s">%s</text></g>`+"\n", node.Kind, box.x, box.y, box.width, svgNodeHeight, svgRadius(node.Kind), color, svgDash(g.Dashed(node.Status)), box.x+box.width/2, box.y+svgNodeHeight/2+4, color, svgEscape(node.Label))
+	}
+
+	builder.WriteString("</svg>\n")
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// svgBox is the position of a node or cluster in svg output
+type svgBox struct {
+	x, y, width, height int
+}
+
+// svgLayout holds the positions of all nodes and clusters of a graph
+type svgLayout struct {
+	nodes         map[*Node]svgBox
+	clusters      map[*Cluster]svgBox
+	width, height int
+}
+
+func newSVGLayout(g *Graph) (l svgLayout) {
+	l.nodes = make(map[*Node]svgBox, len(g.Nodes))
+	l.clusters = make(map[*Cluster]svgBox)
+
+	children := make(map[*Node][]*Node)
+	incoming := make(map[*Node]bool)
+	for _, edge := range g.Edges {
+		children[edge.From] = append(children[edge.From], edge.To)
+		incoming[edge.To] = true
+	}
+
+	// keep children in the same cluster next to each other, so that clusters do not overlap
+	order := make(map[*Node]int, len(g.Nodes))
+	var index int
+	g.Cluster.Walk(func(c, parent *Cluster) {
+		for _, node := range c.Nodes {
+			order[node] = index
+		}
+		index++
+	})
+	for _, nodes := range children {
+		sort.SliceStable(nodes, func(i, j int) bool { return order[nodes[i]] < order[nodes[j]] })
+	}
+
+	// assign a column and a row to each node, visiting children depth first
+	columns := make(map[*Node]int, len(g.Nodes))
+	rows := make(map[*Node]float64, len(g.Nodes))
+	var next float64
+	previous := -1 // cluster of the last node assigned its own row
+	var visit func(node *Node, column int)
+	visit = func(node *Node, column int) {
+		columns[node] = column
+
+		var first, last float64
+		var visited bool
+		for _, child := range children[node] {
+			if _, ok := columns[child]; ok {
+				continue
+			}
+			visit(child, column+1)
+			if !visited {
+				first = rows[child]
+			}
+			last, visited = rows[child], true
+		}
+
+		if !visited {
+			// leave an empty row between clusters to make room for their boxes
+			if previous != -1 && previous != order[node] {
+				next++
+			}
+			previous = order[node]
+
+			rows[node] = next
+			next++
+			return
+		}
+		rows[node] = (first + last) / 2
+	}
+	for _, node := range g.Nodes {
+		if !incoming[node] {
+			visit(node, 0)
+		}
+	}
+	for _, node := range g.Nodes {
+		if _, ok := columns[node]; !ok {
+			visit(node, 0) // only reachable via cycles
+		}
+	}
+
+	// nesting depth of clusters, used to make room for their boxes
+	var maxDepth int
+	var walk func(c *Cluster, depth int)
+	walk = func(c *Cluster, depth int) {
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		for _, child := range c.Clusters {
+			walk(child, depth+1)
+		}
+	}
+	walk(&g.Cluster, 0)
+
+	// width of each column is the width of the widest label within it
+	var widths []int
+	for _, node := range g.Nodes {
+		for len(widths) <= columns[node] {
+			widths = append(widths, 0)
+		}
+		if width := svgLabelWidth(node.Label); width > widths[columns[node]] {
+			widths[columns[node]] = width
+		}
+	}
+
+	margin := maxDepth * (svgClusterPad + svgClusterHead)
+	offsets := make([]int, len(widths))
+	offset := margin
+	for i, width := range widths {
+		offsets[i] = offset
+		offset += width + svgColumnGap
+	}
+	l.width = offset - svgColumnGap + margin
+
+	for _, node := range g.Nodes {
+		column := columns[node]
+		l.nodes[node] = svgBox{
+			x:      offsets[column] + (widths[column]-svgLabelWidth(node.Label))/2,
+			y:      margin + int(rows[node]*svgRowHeight),
+			width:  svgLabelWidth(node.Label),
+			height: svgNodeHeight,
+		}
+	}
+	l.height = margin*2 + int(next)*svgRowHeight
+
+	// each cluster surrounds its own nodes and nested clusters
+	var bound func(c *Cluster) (svgBox, bool)
+	bound = func(c *Cluster) (box svgBox, ok bool) {
+		var x2, y2 int
+		extend := func(other svgBox) {
+			if !ok {
+				box, x2, y2, ok = other, other.x+other.width, other.y+other.height, true
+				return
+			}
+			box.x, box.y = min(box.x, other.x), min(box.y, other.y)
+			x2, y2 = max(x2, other.x+other.width), max(y2, other.y+other.height)
+		}
+		for _, node := range c.Nodes {
+			extend(l.nodes[node])
+		}
+		for _, child := range c.Clusters {
+			if inner, ok := bound(child); ok {
+				extend(inner)
+			}
+		}
+		if !ok {
+			return box, false
+		}
+
+		box = svgBox{
+			x:      box.x - svgClusterPad,
+			y:      box.y - svgClusterPad - svgClusterHead,
+			width:  x2 - box.x + 2*svgClusterPad,
+			height: y2 - box.y + 2*svgClusterPad + svgClusterHead,
+		}
+		l.clusters[c] = box
+		return box, true
+	}
+	for _, child := range g.Cluster.Clusters {
+		bound(child)
+	}
+
+	return l
+}
+
+// svgArrow returns the points of an arrow head at the end of the line from (x1, y1) to (x2, y2).
+// Arrow heads are drawn explicitly, as markers would require ids that are unique within an embedding document.
+func svgArrow(x1, y1, x2, y2 int) string {
+	dx, dy := float64(x2-x1), float64(y2-y1)
+	length := math.Hypot(dx, dy)
+	if length == 0 {
+		return ""
+	}
+	dx, dy = dx/length*svgArrowSize, dy/length*svgArrowSize
+
+	bx, by := float64(x2)-dx, float64(y2)-dy
+	return fmt.Sprintf("%d,%d %.1f,%.1f %.1f,%.1f", x2, y2, bx-dy/2, by+dx/2, bx+dy/2, by-dx/2)
+}
+
+// svgLabelWidth returns the width of a node with the given label
+func svgLabelWidth(label string) int {
+	return len([]rune(label))*svgCharWidth + 2*svgClusterPad
+}
+
+// svgRadius returns the corner radius to use for nodes of the given kind
+func svgRadius(kind NodeKind) int {
+	if kind == DatatypeNode {
+		return 0
+	}
+	return svgNodeHeight / 2
+}
+
+// svgColor returns the color to use for strokes and text, defaulting to black
+func svgColor(color string) string {
+	if color == "" {
+		return "black"
+	}
+	return svgEscape(color)
+}
+
+// svgDash returns the attribute to append for dashed nodes or edges
+func svgDash(dashed bool) string {
+	if !dashed {
+		return ""
+	}
+	return ` stroke-dasharray="5 5"`
+}
+
+// svgEscape escapes a string for use in svg text and attributes
+func svgEscape(value string) string {
+	var builder strings.Builder
+	xml.EscapeText(&builder, []byte(value))
+	return builder.String()
+}
@@ -112,3 +112,35 @@ func (bundle Bundle) Paths() []Path {
 	}
 	return paths
 }
+
+// RelativePathArray returns the part of the path array starting at the last class of the given bundle.
+// The first element of the returned slice is that class; subsequent elements alternate between properties and classes.
+//
+// If bundle is nil, returns the entire path array.
+func (p Path) RelativePathArray(bundle *Bundle) []string {
+	var offset int
+	if bundle != nil {
+		offset = len(bundle.PathArray) - 1
+	}
+	if offset < 0 {
+		offset = 0
+	}
+	if offset%2 == 1 {
+		offset--
+	}
+	if offset > len(p.PathArray) {
+		return nil
+	}
+	return p.PathArray[offset:]
+}
+
+// LastClass returns the last class in the path array, or the empty string if the path array is empty.
+func (p Path) LastClass() string {
+	if len(p.PathArray) == 0 {
+		return ""
+	}
+	if len(p.PathArray)%2 == 0 {
+		return p.PathArray[len(p.PathArray)-2]
+	}
+	return p.PathArray[len(p.PathArray)-1]
+}
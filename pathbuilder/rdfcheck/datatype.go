@@ -0,0 +1,102 @@
+package rdfcheck
+
+// cspell:words xsd
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/FAU-CDI/drincw/internal/rdf"
+)
+
+// compatible maps an expected xsd datatype to other datatypes that are also accepted
+var compatible = map[string][]string{
+	rdf.XSDNamespace + "integer":  {"int", "long", "short", "byte", "nonNegativeInteger", "positiveInteger", "negativeInteger", "nonPositiveInteger", "unsignedInt", "unsignedLong", "unsignedShort", "unsignedByte"},
+	rdf.XSDNamespace + "decimal":  {"integer", "int", "long"},
+	rdf.XSDNamespace + "double":   {"float", "decimal", "integer", "int", "long"},
+	rdf.XSDNamespace + "dateTime": {"date", "dateTimeStamp", "gYear", "gYearMonth"},
+}
+
+// lexical contains checks for the lexical form of untyped literals
+var lexical = map[string]func(string) bool{
+	rdf.XSDNamespace + "integer": func(s string) bool {
+		_, err := strconv.ParseInt(s, 10, 64)
+		return err == nil
+	},
+	rdf.XSDNamespace + "decimal": func(s string) bool {
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	},
+	rdf.XSDNamespace + "double": func(s string) bool {
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	},
+	rdf.XSDNamespace + "boolean": func(s string) bool {
+		return s == "true" || s == "false" || s == "1" || s == "0"
+	},
+	rdf.XSDNamespace + "dateTime": func(s string) bool {
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02", "2006-01", "2006"} {
+			if _, err := time.Parse(layout, s); err == nil {
+				return true
+			}
+		}
+		return false
+	},
+}
+
+// fits checks if the given value fits the expected datatype.
+// If not, returns a human-readable reason.
+func fits(value rdf.Term, expected string) (ok bool, detail string) {
+	if expected == "" {
+		if value.IsResource() {
+			return true, ""
+		}
+		return false, "expected a reference, got literal " + value.String()
+	}
+
+	if value.Kind != rdf.Literal {
+		return false, "expected a literal, got " + value.String()
+	}
+
+	// strings accept anything
+	if expected == rdf.XSDString {
+		return true, ""
+	}
+
+	actual := value.LiteralDatatype()
+	if actual == expected {
+		return true, ""
+	}
+	for _, name := range compatible[expected] {
+		if actual == rdf.XSDNamespace+name {
+			return true, ""
+		}
+	}
+
+	// untyped literals are accepted as long as their lexical form is valid
+	if actual == rdf.XSDString || actual == rdf.LangString {
+		check, ok := lexical[expected]
+		if !ok || check(value.Value) {
+			return true, ""
+		}
+		return false, strconv.Quote(value.Value) + " is not a valid " + shorten(expected)
+	}
+
+	return false, "expected " + shorten(expected) + ", got " + shorten(actual)
+}
+
+// shorten shortens an xsd uri into a prefixed name
+func shorten(uri string) string {
+	if strings.HasPrefix(uri, rdf.XSDNamespace) {
+		return "xsd:" + strings.TrimPrefix(uri, rdf.XSDNamespace)
+	}
+	return "<" + uri + ">"
+}
+
+func pluralize(count int, word string) string {
+	if count == 1 {
+		return "1 " + word
+	}
+	return strconv.Itoa(count) + " " + word + "s"
+}
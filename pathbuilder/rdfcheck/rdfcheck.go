@@ -0,0 +1,230 @@
+// Package rdfcheck validates rdf data against the cardinalities and datatypes of a pathbuilder.
+package rdfcheck
+
+// cspell:words pathbuilder rdfcheck
+
+import (
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Kind represents the kind of an issue
+type Kind string
+
+const (
+	// Cardinality indicates that an entity has more values than the cardinality of the field permits
+	Cardinality Kind = "cardinality"
+
+	// Dangling indicates an intermediate node that matches a prefix of the path, but never reaches the datatype property
+	Dangling Kind = "dangling"
+
+	// Datatype indicates a value that does not fit the field type
+	Datatype Kind = "datatype"
+)
+
+// Kinds contains all kinds of issues, in the order they are reported in
+var Kinds = []Kind{Cardinality, Dangling, Datatype}
+
+// Options determine how data is checked
+type Options struct {
+	// Examples is the maximum number of examples to keep per field and kind of issue.
+	// A non-positive number keeps no examples.
+	Examples int
+}
+
+// Issue represents a single problem found in the data
+type Issue struct {
+	Kind   Kind   `json:"kind"`
+	Entity string `json:"entity"`         // uri of the entity the issue was found in
+	Node   string `json:"node,omitempty"` // uri of the offending node or the offending value (if any)
+	Detail string `json:"detail"`         // human-readable details
+}
+
+// FieldReport contains the issues found for a single field
+type FieldReport struct {
+	Field pathbuilder.Field
+
+	Counts   map[Kind]int // total number of issues by kind
+	Examples []Issue      // examples of issues, limited by Options.Examples
+}
+
+// Count returns the total number of issues in this report
+func (fr FieldReport) Count() (count int) {
+	for _, c := range fr.Counts {
+		count += c
+	}
+	return
+}
+
+// BundleReport contains the issues found within a single bundle
+type BundleReport struct {
+	Bundle   *pathbuilder.Bundle
+	Entities int // number of entities found for the bundle
+
+	Fields []FieldReport // reports for fields that have at least one issue
+}
+
+// Report is the result of checking a graph against a pathbuilder
+type Report struct {
+	Bundles []BundleReport `json:"bundles"` // bundles (including child bundles) in pathbuilder order
+}
+
+// Count returns the total number of issues in this report
+func (r Report) Count() (count int) {
+	for _, b := range r.Bundles {
+		for _, f := range b.Fields {
+			count += f.Count()
+		}
+	}
+	return
+}
+
+// Check checks the given graph against the pathbuilder.
+func Check(pb pathbuilder.Pathbuilder, g *rdf.Graph, opts Options) (r Report) {
+	for _, bundle := range pb.Bundles() {
+		if len(bundle.PathArray) == 0 {
+			continue
+		}
+		entities := g.Instances(bundle.PathArray[0])
+		r.Bundles = checkBundle(r.Bundles, g, bundle, entities, opts)
+	}
+	return
+}
+
+func checkBundle(reports []BundleReport, g *rdf.Graph, bundle *pathbuilder.Bundle, entities []rdf.Term, opts Options) []BundleReport {
+	report := BundleReport{Bundle: bundle, Entities: len(entities)}
+
+	for _, field := range bundle.Fields() {
+		if !field.Enabled {
+			continue
+		}
+		fr := checkField(g, bundle, field, entities, opts)
+		if fr.Count() == 0 {
+			continue
+		}
+		report.Fields = append(report.Fields, fr)
+	}
+	reports = append(reports, report)
+
+	for _, child := range bundle.Bundles() {
+		if !child.Enabled {
+			continue
+		}
+
+		// find the entities of the child bundle by walking from the entities of this bundle
+		var children []rdf.Term
+		seen := make(map[rdf.Term]struct{})
+		for _, entity := range entities {
+			ends, _ := walk(g, entity, steps(child.Path, bundle))
+			for _, end := range ends {
+				if _, ok := seen[end]; ok {
+					continue
+				}
+				seen[end] = struct{}{}
+				children = append(children, end)
+			}
+		}
+		reports = checkBundle(reports, g, child, children, opts)
+	}
+
+	return reports
+}
+
+func checkField(g *rdf.Graph, bundle *pathbuilder.Bundle, field pathbuilder.Field, entities []rdf.Term, opts Options) FieldReport {
+	fr := FieldReport{
+		Field:  field,
+		Counts: make(map[Kind]int),
+	}
+	examples := make(map[Kind]int)
+	add := func(issue Issue) {
+		fr.Counts[issue.Kind]++
+		if examples[issue.Kind] >= opts.Examples {
+			return
+		}
+		examples[issue.Kind]++
+		fr.Examples = append(fr.Examples, issue)
+	}
+
+	datatype := field.Datatype()
+	expected := field.XSDType()
+
+	for _, entity := range entities {
+		ends, dangling := walk(g, entity, steps(field.Path, bundle))
+		for _, node := range dangling {
+			add(Issue{Kind: Dangling, Entity: entity.Value, Node: node.Value, Detail: "path does not continue"})
+		}
+
+		// find the actual values of the field
+		var values []rdf.Term
+		if datatype == "" {
+			values = ends
+		} else {
+			for _, end := range ends {
+				objects := g.Objects(end, datatype)
+				if len(objects) == 0 {
+					add(Issue{Kind: Dangling, Entity: entity.Value, Node: end.Value, Detail: "missing datatype property"})
+				}
+				values = append(values, objects...)
+			}
+		}
+
+		if field.Cardinality > 0 && len(values) > field.Cardinality {
+			add(Issue{
+				Kind:   Cardinality,
+				Entity: entity.Value,
+				Detail: pluralize(len(values), "value") + ", but cardinality is " + pluralize(field.Cardinality, "value"),
+			})
+		}
+
+		if datatype == "" {
+			continue
+		}
+		for _, value := range values {
+			if ok, detail := fits(value, expected); !ok {
+				add(Issue{Kind: Datatype, Entity: entity.Value, Node: value.Value, Detail: detail})
+			}
+		}
+	}
+
+	return fr
+}
+
+// step represents a single step of a path, that is a property leading to a node of a given class
+type step struct {
+	Property string
+	Class    string
+}
+
+// steps returns the steps of the path that occur after the given bundle
+func steps(path pathbuilder.Path, bundle *pathbuilder.Bundle) []step {
+	array := path.RelativePathArray(bundle)
+	steps := make([]step, 0, len(array)/2)
+	for i := 1; i+1 < len(array); i += 2 {
+		steps = append(steps, step{Property: array[i], Class: array[i+1]})
+	}
+	return steps
+}
+
+// walk walks the given steps starting at the given node.
+// ends contains the nodes reached after all steps, dangling contains intermediate nodes that do not continue.
+func walk(g *rdf.Graph, start rdf.Term, steps []step) (ends []rdf.Term, dangling []rdf.Term) {
+	frontier := []rdf.Term{start}
+	for i, step := range steps {
+		var next []rdf.Term
+		for _, node := range frontier {
+			var found bool
+			for _, object := range g.Objects(node, step.Property) {
+				if !object.IsResource() || !g.HasType(object, step.Class) {
+					continue
+				}
+				found = true
+				next = append(next, object)
+			}
+			if !found && i > 0 {
+				dangling = append(dangling, node)
+			}
+		}
+		frontier = next
+	}
+	return frontier, dangling
+}
@@ -0,0 +1,107 @@
+package rdfcheck
+
+// cspell:words pathbuilder rdfcheck
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared by the tests of all pathbuilder packages
+var testPathbuilder = filepath.Join("..", "testdata", "pathbuilder.xml")
+
+const testData = `
+<http://example.com/alice> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Person> .
+<http://example.com/alice> <http://example.com/hasName> <http://example.com/alice/name1> .
+<http://example.com/alice/name1> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Name> .
+<http://example.com/alice/name1> <http://example.com/value> "Alice" .
+<http://example.com/alice> <http://example.com/hasName> <http://example.com/alice/name2> .
+<http://example.com/alice/name2> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Name> .
+<http://example.com/alice/name2> <http://example.com/value> "Ally" .
+<http://example.com/alice> <http://example.com/born> <http://example.com/alice/birth> .
+<http://example.com/alice/birth> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Birth> .
+<http://example.com/alice/birth> <http://example.com/in> <http://example.com/alice/place> .
+<http://example.com/alice/place> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Place> .
+<http://example.com/alice/place> <http://example.com/label> "Erlangen" .
+<http://example.com/alice> <http://example.com/participated> <http://example.com/alice/event> .
+<http://example.com/alice/event> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Event> .
+<http://example.com/alice/event> <http://example.com/hasName> <http://example.com/alice/event/title> .
+<http://example.com/alice/event/title> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Name> .
+<http://example.com/alice/event/title> <http://example.com/value> "Conference" .
+
+<http://example.com/bob> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Person> .
+<http://example.com/bob> <http://example.com/born> <http://example.com/bob/birth> .
+<http://example.com/bob/birth> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Birth> .
+<http://example.com/bob/birth> <http://example.com/at> <http://example.com/bob/year> .
+<http://example.com/bob/year> <http://www.w3.org/1999/02/22-rdf-syntax-ns#type> <http://example.com/Year> .
+<http://example.com/bob/year> <http://example.com/value> "nineteen-ninety" .
+`
+
+func TestCheck(t *testing.T) {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := rdf.ReadGraph(strings.NewReader(testData))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report := Check(pb, g, Options{Examples: 10})
+
+	type want struct {
+		bundle   string
+		entities int
+		issues   map[string]map[Kind]int // field => kind => count
+	}
+	wants := []want{
+		{
+			bundle:   "person",
+			entities: 2,
+			issues: map[string]map[Kind]int{
+				"name":        {Cardinality: 1},
+				"alias":       {Dangling: 2}, // names without an alias
+				"birth_year":  {Dangling: 1, Datatype: 1},
+				"birth_place": {Dangling: 1}, // a birth without a place
+			},
+		},
+		{
+			bundle:   "event",
+			entities: 1,
+			issues:   map[string]map[Kind]int{},
+		},
+		{
+			bundle:   "participants",
+			entities: 0,
+			issues:   map[string]map[Kind]int{},
+		},
+	}
+
+	if len(report.Bundles) != len(wants) {
+		t.Fatalf("Check() returned %d bundles, want %d", len(report.Bundles), len(wants))
+	}
+	for i, want := range wants {
+		got := report.Bundles[i]
+		if got.Bundle.MachineName() != want.bundle {
+			t.Errorf("Check() bundle %d = %q, want %q", i, got.Bundle.MachineName(), want.bundle)
+		}
+		if got.Entities != want.entities {
+			t.Errorf("Check() bundle %q has %d entities, want %d", want.bundle, got.Entities, want.entities)
+		}
+		if len(got.Fields) != len(want.issues) {
+			t.Errorf("Check() bundle %q has %d fields with issues, want %d", want.bundle, len(got.Fields), len(want.issues))
+		}
+		for _, field := range got.Fields {
+			wantCounts := want.issues[field.Field.MachineName()]
+			for _, kind := range Kinds {
+				if field.Counts[kind] != wantCounts[kind] {
+					t.Errorf("Check() field %q has %d %s issues, want %d", field.Field.MachineName(), field.Counts[kind], kind, wantCounts[kind])
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,99 @@
+package rdfcheck
+
+// cspell:words pathbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteText writes a human-readable version of this report to w.
+func (r Report) WriteText(w io.Writer) error {
+	for _, b := range r.Bundles {
+		if len(b.Fields) == 0 {
+			continue
+		}
+		if _, err := fmt.Fprintf(w, "Bundle %s (%q): %d entities\n", b.Bundle.MachineName(), b.Bundle.Name, b.Entities); err != nil {
+			return err
+		}
+
+		for _, f := range b.Fields {
+			if _, err := fmt.Fprintf(w, "  Field %s (%q):\n", f.Field.MachineName(), f.Field.Name); err != nil {
+				return err
+			}
+			for _, kind := range Kinds {
+				count := f.Counts[kind]
+				if count == 0 {
+					continue
+				}
+				if _, err := fmt.Fprintf(w, "    %s: %d\n", kind, count); err != nil {
+					return err
+				}
+				for _, issue := range f.Examples {
+					if issue.Kind != kind {
+						continue
+					}
+					if _, err := fmt.Fprintf(w, "      %s\n", issue); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%s found\n", pluralize(r.Count(), "issue"))
+	return err
+}
+
+// String formats this issue as a single line
+func (issue Issue) String() string {
+	if issue.Node == "" {
+		return fmt.Sprintf("<%s>: %s", issue.Entity, issue.Detail)
+	}
+	return fmt.Sprintf("<%s>: %s (%s)", issue.Entity, issue.Detail, issue.Node)
+}
+
+// bundleReportJSON is the JSON representation of a BundleReport
+type bundleReportJSON struct {
+	Bundle   string        `json:"bundle"`
+	Name     string        `json:"name"`
+	Entities int           `json:"entities"`
+	Fields   []FieldReport `json:"fields"`
+}
+
+// MarshalJSON marshals this report as JSON
+func (br BundleReport) MarshalJSON() ([]byte, error) {
+	fields := br.Fields
+	if fields == nil {
+		fields = []FieldReport{}
+	}
+	return json.Marshal(bundleReportJSON{
+		Bundle:   br.Bundle.MachineName(),
+		Name:     br.Bundle.Name,
+		Entities: br.Entities,
+		Fields:   fields,
+	})
+}
+
+// fieldReportJSON is the JSON representation of a FieldReport
+type fieldReportJSON struct {
+	Field    string       `json:"field"`
+	Name     string       `json:"name"`
+	Counts   map[Kind]int `json:"counts"`
+	Examples []Issue      `json:"examples"`
+}
+
+// MarshalJSON marshals this report as JSON
+func (fr FieldReport) MarshalJSON() ([]byte, error) {
+	examples := fr.Examples
+	if examples == nil {
+		examples = []Issue{}
+	}
+	return json.Marshal(fieldReportJSON{
+		Field:    fr.Field.MachineName(),
+		Name:     fr.Field.Name,
+		Counts:   fr.Counts,
+		Examples: examples,
+	})
+}
@@ -0,0 +1,189 @@
+// Package shacl generates SHACL shapes from a pathbuilder
+package shacl
+
+// cspell:words pathbuilder shacl rdfs xsd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// DefaultBase is the default base iri for generated shapes
+const DefaultBase = "urn:drincw:shape:"
+
+// Options determine how shapes are generated
+type Options struct {
+	Prefixes map[string]string // additional prefixes to use in the output
+
+	Base string // base iri for generated shapes; defaults to DefaultBase
+}
+
+// ShapeIRI returns the iri of the node shape generated for the given bundle
+func (opts Options) ShapeIRI(bundle *pathbuilder.Bundle) string {
+	return opts.base() + bundle.MachineName()
+}
+
+func (opts Options) base() string {
+	if opts.Base == "" {
+		return DefaultBase
+	}
+	return opts.Base
+}
+
+// prefixes returns the prefixes to use for the output
+func (opts Options) prefixes() rdf.Prefixes {
+	prefixes := rdf.Prefixes{
+		"sh":    rdf.SHACLNamespace,
+		"rdfs":  rdf.RDFSNamespace,
+		"xsd":   rdf.XSDNamespace,
+		"shape": opts.base(),
+	}
+	for name, ns := range opts.Prefixes {
+		prefixes[name] = ns
+	}
+	return prefixes
+}
+
+// Write writes SHACL shapes for all bundles in the pathbuilder as Turtle into w
+func Write(w io.Writer, pb pathbuilder.Pathbuilder, opts Options) error {
+	return WriteBundles(w, opts, pb.Bundles()...)
+}
+
+// WriteBundles writes SHACL shapes for the given bundles (and their child bundles) as Turtle into w.
+func WriteBundles(w io.Writer, opts Options, bundles ...*pathbuilder.Bundle) error {
+	prefixes := opts.prefixes()
+
+	var builder strings.Builder
+	builder.WriteString(prefixes.Turtle())
+
+	for _, bundle := range bundles {
+		if bundle == nil || !bundle.Enabled {
+			continue
+		}
+		writeBundle(&builder, prefixes, opts, bundle)
+	}
+
+	_, err := io.WriteString(w, builder.String())
+	return err
+}
+
+// writeBundle writes a node shape for the bundle and all its enabled child bundles
+func writeBundle(builder *strings.Builder, prefixes rdf.Prefixes, opts Options, bundle *pathbuilder.Bundle) {
+	builder.WriteString("\n")
+	builder.WriteString(prefixes.FormatIRI(opts.ShapeIRI(bundle)))
+	builder.WriteString("\n    a sh:NodeShape ;\n")
+	writeLabel(builder, "    ", "rdfs:label", bundle.Name)
+
+	class := bundle.LastClass()
+	if class != "" {
+		if bundle.IsToplevel() {
+			fmt.Fprintf(builder, "    sh:targetClass %s ;\n", prefixes.FormatIRI(class))
+		} else {
+			fmt.Fprintf(builder, "    sh:class %s ;\n", prefixes.FormatIRI(class))
+		}
+	}
+
+	for _, field := range bundle.Fields() {
+		if !field.Enabled {
+			continue
+		}
+		writeField(builder, prefixes, bundle, field)
+	}
+
+	children := make([]*pathbuilder.Bundle, 0, len(bundle.ChildBundles))
+	for _, child := range bundle.Bundles() {
+		if !child.Enabled {
+			continue
+		}
+		writeChild(builder, prefixes, opts, bundle, child)
+		children = append(children, child)
+	}
+
+	builder.WriteString(".\n")
+
+	for _, child := range children {
+		writeBundle(builder, prefixes, opts, child)
+	}
+}
+
+// writeField writes a property shape for the given field
+func writeField(builder *strings.Builder, prefixes rdf.Prefixes, bundle *pathbuilder.Bundle, field pathbuilder.Field) {
+	path := properties(field.Path, bundle)
+	if datatype := field.Datatype(); datatype != "" {
+		path = append(path, datatype)
+	}
+	if len(path) == 0 {
+		return
+	}
+
+	builder.WriteString("    sh:property [\n")
+	writePath(builder, prefixes, path)
+	writeLabel(builder, "        ", "sh:name", field.Name)
+	writeLabel(builder, "        ", "sh:description", field.Description)
+	if field.Cardinality > 0 {
+		fmt.Fprintf(builder, "        sh:maxCount %d ;\n", field.Cardinality)
+	}
+	if typ := field.XSDType(); typ != "" && field.Datatype() != "" {
+		fmt.Fprintf(builder, "        sh:datatype %s ;\n", prefixes.FormatIRI(typ))
+	} else if class := field.LastClass(); class != "" {
+		builder.WriteString("        sh:nodeKind sh:BlankNodeOrIRI ;\n")
+		fmt.Fprintf(builder, "        sh:class %s ;\n", prefixes.FormatIRI(class))
+	}
+	builder.WriteString("    ] ;\n")
+}
+
+// writeChild writes a property shape linking bundle to the node shape of a child bundle
+func writeChild(builder *strings.Builder, prefixes rdf.Prefixes, opts Options, bundle *pathbuilder.Bundle, child *pathbuilder.Bundle) {
+	path := properties(child.Path, bundle)
+	if len(path) == 0 {
+		return
+	}
+
+	builder.WriteString("    sh:property [\n")
+	writePath(builder, prefixes, path)
+	writeLabel(builder, "        ", "sh:name", child.Name)
+	if child.Cardinality > 0 {
+		fmt.Fprintf(builder, "        sh:maxCount %d ;\n", child.Cardinality)
+	}
+	fmt.Fprintf(builder, "        sh:node %s ;\n", prefixes.FormatIRI(opts.ShapeIRI(child)))
+	builder.WriteString("    ] ;\n")
+}
+
+// writePath writes an sh:path declaration.
+// A single property is written as a predicate path, several properties as a sequence path.
+func writePath(builder *strings.Builder, prefixes rdf.Prefixes, path []string) {
+	builder.WriteString("        sh:path ")
+	if len(path) == 1 {
+		builder.WriteString(prefixes.FormatIRI(path[0]))
+	} else {
+		builder.WriteString("(")
+		for _, property := range path {
+			builder.WriteString(" ")
+			builder.WriteString(prefixes.FormatIRI(property))
+		}
+		builder.WriteString(" )")
+	}
+	builder.WriteString(" ;\n")
+}
+
+// writeLabel writes a literal-valued property, unless value is empty
+func writeLabel(builder *strings.Builder, indent, property, value string) {
+	if value == "" {
+		return
+	}
+	fmt.Fprintf(builder, "%s%s %s ;\n", indent, property, rdf.NewLiteral(value, "").String())
+}
+
+// properties returns the properties in path that occur after the given bundle
+func properties(path pathbuilder.Path, bundle *pathbuilder.Bundle) []string {
+	array := path.RelativePathArray(bundle)
+	properties := make([]string, 0, len(array)/2+1)
+	for i := 1; i < len(array); i += 2 {
+		properties = append(properties, array[i])
+	}
+	return properties
+}
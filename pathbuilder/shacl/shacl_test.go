@@ -0,0 +1,104 @@
+package shacl
+
+// cspell:words pathbuilder shacl
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared by the tests of all pathbuilder packages
+var testPathbuilder = filepath.Join("..", "testdata", "pathbuilder.xml")
+
+func ExampleWrite() {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		panic(err)
+	}
+
+	err = Write(os.Stdout, pb, Options{
+		Base:     "http://example.com/shapes/",
+		Prefixes: map[string]string{"ex": "http://example.com/"},
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	// Output: @prefix ex: <http://example.com/> .
+	// @prefix rdfs: <http://www.w3.org/2000/01/rdf-schema#> .
+	// @prefix sh: <http://www.w3.org/ns/shacl#> .
+	// @prefix shape: <http://example.com/shapes/> .
+	// @prefix xsd: <http://www.w3.org/2001/XMLSchema#> .
+	//
+	// shape:person
+	//     a sh:NodeShape ;
+	//     rdfs:label "Person" ;
+	//     sh:targetClass ex:Person ;
+	//     sh:property [
+	//         sh:path ( ex:hasName ex:value ) ;
+	//         sh:name "Name" ;
+	//         sh:maxCount 1 ;
+	//         sh:datatype xsd:string ;
+	//     ] ;
+	//     sh:property [
+	//         sh:path ( ex:hasName ex:alias ) ;
+	//         sh:name "Alias" ;
+	//         sh:datatype xsd:string ;
+	//     ] ;
+	//     sh:property [
+	//         sh:path ( ex:born ex:at ex:value ) ;
+	//         sh:name "Birth Year" ;
+	//         sh:maxCount 1 ;
+	//         sh:datatype xsd:integer ;
+	//     ] ;
+	//     sh:property [
+	//         sh:path ( ex:born ex:in ex:label ) ;
+	//         sh:name "Birth Place" ;
+	//         sh:maxCount 1 ;
+	//         sh:datatype xsd:string ;
+	//     ] ;
+	//     sh:property [
+	//         sh:path ex:knows ;
+	//         sh:name "Knows" ;
+	//         sh:nodeKind sh:BlankNodeOrIRI ;
+	//         sh:class ex:Person ;
+	//     ] ;
+	//     sh:property [
+	//         sh:path ex:participated ;
+	//         sh:name "Event" ;
+	//         sh:node shape:event ;
+	//     ] ;
+	// .
+	//
+	// shape:event
+	//     a sh:NodeShape ;
+	//     rdfs:label "Event" ;
+	//     sh:class ex:Event ;
+	//     sh:property [
+	//         sh:path ( ex:hasName ex:value ) ;
+	//         sh:name "Title" ;
+	//         sh:maxCount 1 ;
+	//         sh:datatype xsd:string ;
+	//     ] ;
+	//     sh:property [
+	//         sh:path ex:hasParticipant ;
+	//         sh:name "Participants" ;
+	//         sh:maxCount 2 ;
+	//         sh:node shape:participants ;
+	//     ] ;
+	// .
+	//
+	// shape:participants
+	//     a sh:NodeShape ;
+	//     rdfs:label "Participants" ;
+	//     sh:class ex:Person ;
+	//     sh:property [
+	//         sh:path ( ex:hasName ex:value ) ;
+	//         sh:name "Participant Name" ;
+	//         sh:maxCount 1 ;
+	//         sh:datatype xsd:string ;
+	//     ] ;
+	// .
+}
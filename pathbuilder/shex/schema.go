@@ -0,0 +1,150 @@
+package shex
+
+// cspell:words shex shexj jsonld
+
+import "encoding/json"
+
+// Schema represents a ShEx schema in ShExJ form.
+//
+// It can be passed to json.Marshal to obtain ShExJ, or written as ShExC using WriteShExC.
+type Schema struct {
+	Shapes []ShapeDecl
+}
+
+// ShapeDecl declares a named shape expression
+type ShapeDecl struct {
+	ID        string
+	ShapeExpr ShapeExpr
+}
+
+// ShapeExpr is a shape expression, one of *Shape, *NodeConstraint or ShapeRef.
+type ShapeExpr interface {
+	isShapeExpr()
+}
+
+// Shape is a ShEx shape
+type Shape struct {
+	Extra      []string   // predicates that may have additional values not matching the expression
+	Expression TripleExpr // expression the shape has to match (may be nil)
+}
+
+// NodeConstraint constrains a single node
+type NodeConstraint struct {
+	NodeKind string   // "iri", "bnode", "nonliteral", "literal" or empty
+	Datatype string   // datatype of literals (if any)
+	Values   []string // allowed iri values (if any)
+}
+
+// ShapeRef references a shape declared elsewhere by id
+type ShapeRef string
+
+func (*Shape) isShapeExpr()          {}
+func (*NodeConstraint) isShapeExpr() {}
+func (ShapeRef) isShapeExpr()        {}
+
+// TripleExpr is a triple expression, one of *EachOf or *TripleConstraint.
+type TripleExpr interface {
+	isTripleExpr()
+}
+
+// EachOf matches if each of the expressions matches
+type EachOf struct {
+	Expressions []TripleExpr
+}
+
+// Unbounded represents an unbounded maximum cardinality
+const Unbounded = -1
+
+// TripleConstraint constrains the triples with a specific predicate
+type TripleConstraint struct {
+	Predicate string
+	ValueExpr ShapeExpr // may be nil
+
+	Min, Max int // cardinality; Max may be Unbounded
+}
+
+func (*EachOf) isTripleExpr()           {}
+func (*TripleConstraint) isTripleExpr() {}
+
+// ShExJContext is the json-ld context of ShExJ documents
+const ShExJContext = "http://www.w3.org/ns/shex.jsonld"
+
+func (schema Schema) MarshalJSON() ([]byte, error) {
+	shapes := schema.Shapes
+	if shapes == nil {
+		shapes = []ShapeDecl{}
+	}
+	return json.Marshal(struct {
+		Context string      `json:"@context"`
+		Type    string      `json:"type"`
+		Shapes  []ShapeDecl `json:"shapes"`
+	}{
+		Context: ShExJContext,
+		Type:    "Schema",
+		Shapes:  shapes,
+	})
+}
+
+func (decl ShapeDecl) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type      string    `json:"type"`
+		ID        string    `json:"id"`
+		ShapeExpr ShapeExpr `json:"shapeExpr"`
+	}{
+		Type:      "ShapeDecl",
+		ID:        decl.ID,
+		ShapeExpr: decl.ShapeExpr,
+	})
+}
+
+func (shape *Shape) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type       string     `json:"type"`
+		Extra      []string   `json:"extra,omitempty"`
+		Expression TripleExpr `json:"expression,omitempty"`
+	}{
+		Type:       "Shape",
+		Extra:      shape.Extra,
+		Expression: shape.Expression,
+	})
+}
+
+func (nc *NodeConstraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type     string   `json:"type"`
+		NodeKind string   `json:"nodeKind,omitempty"`
+		Datatype string   `json:"datatype,omitempty"`
+		Values   []string `json:"values,omitempty"`
+	}{
+		Type:     "NodeConstraint",
+		NodeKind: nc.NodeKind,
+		Datatype: nc.Datatype,
+		Values:   nc.Values,
+	})
+}
+
+func (eo *EachOf) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type        string       `json:"type"`
+		Expressions []TripleExpr `json:"expressions"`
+	}{
+		Type:        "EachOf",
+		Expressions: eo.Expressions,
+	})
+}
+
+func (tc *TripleConstraint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Type      string    `json:"type"`
+		Predicate string    `json:"predicate"`
+		ValueExpr ShapeExpr `json:"valueExpr,omitempty"`
+		Min       int       `json:"min"`
+		Max       int       `json:"max"`
+	}{
+		Type:      "TripleConstraint",
+		Predicate: tc.Predicate,
+		ValueExpr: tc.ValueExpr,
+		Min:       tc.Min,
+		Max:       tc.Max,
+	})
+}
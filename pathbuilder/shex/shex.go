@@ -0,0 +1,246 @@
+// Package shex generates ShEx schemas from a pathbuilder
+package shex
+
+// cspell:words pathbuilder shex shexc shexj
+
+import (
+	"github.com/FAU-CDI/drincw/internal/rdf"
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// DefaultBase is the default base iri for generated shapes
+const DefaultBase = "urn:drincw:shape:"
+
+// Options determine how a schema is generated
+type Options struct {
+	Base string // base iri for generated shapes; defaults to DefaultBase
+}
+
+// ShapeID returns the id of the shape generated for the given bundle
+func (opts Options) ShapeID(bundle *pathbuilder.Bundle) string {
+	return opts.base() + bundle.MachineName()
+}
+
+func (opts Options) base() string {
+	if opts.Base == "" {
+		return DefaultBase
+	}
+	return opts.Base
+}
+
+// New generates a new schema for all bundles in the pathbuilder
+func New(pb pathbuilder.Pathbuilder, opts Options) Schema {
+	return NewForBundles(opts, pb.Bundles()...)
+}
+
+// NewForBundles generates a new schema for the given bundles (and their child bundles).
+//
+// Each bundle is turned into a shape, with nested shapes for intermediate nodes of paths.
+// Child bundles and entity references whose class is the start class of a generated bundle are turned into shape references.
+func NewForBundles(opts Options, bundles ...*pathbuilder.Bundle) (schema Schema) {
+	g := generator{
+		opts:    opts,
+		classes: make(map[string]string),
+	}
+
+	// find the shapes that entity references can point to
+	for _, bundle := range bundles {
+		if bundle == nil || !bundle.Enabled || len(bundle.PathArray) == 0 {
+			continue
+		}
+		if _, ok := g.classes[bundle.PathArray[0]]; !ok {
+			g.classes[bundle.PathArray[0]] = opts.ShapeID(bundle)
+		}
+	}
+
+	for _, bundle := range bundles {
+		if bundle == nil || !bundle.Enabled {
+			continue
+		}
+		schema.Shapes = g.addBundle(schema.Shapes, bundle)
+	}
+	return schema
+}
+
+type generator struct {
+	opts    Options
+	classes map[string]string // start classes of top-level bundles to their shape ids
+}
+
+// addBundle adds shapes for bundle and its child bundles to decls
+func (g generator) addBundle(decls []ShapeDecl, bundle *pathbuilder.Bundle) []ShapeDecl {
+	root := newNode(bundle.LastClass())
+
+	for _, field := range bundle.Fields() {
+		if !field.Enabled {
+			continue
+		}
+		g.addField(root, bundle, field)
+	}
+
+	children := make([]*pathbuilder.Bundle, 0, len(bundle.ChildBundles))
+	for _, child := range bundle.Bundles() {
+		if !child.Enabled {
+			continue
+		}
+		root.insert(child.RelativePathArray(bundle), &TripleConstraint{
+			ValueExpr: ShapeRef(g.opts.ShapeID(child)),
+		}, maxCardinality(child.Cardinality))
+		children = append(children, child)
+	}
+
+	decls = append(decls, ShapeDecl{
+		ID:        g.opts.ShapeID(bundle),
+		ShapeExpr: root.shape(),
+	})
+	for _, child := range children {
+		decls = g.addBundle(decls, child)
+	}
+	return decls
+}
+
+// addField adds the constraints for field to root
+func (g generator) addField(root *node, bundle *pathbuilder.Bundle, field pathbuilder.Field) {
+	array := field.RelativePathArray(bundle)
+
+	// regular field: the datatype property leads to a literal
+	if datatype := field.Datatype(); datatype != "" {
+		parent := root.descend(array, maxCardinality(field.Cardinality))
+		parent.leaves = append(parent.leaves, &TripleConstraint{
+			Predicate: datatype,
+			ValueExpr: literalConstraint(field),
+			Min:       0,
+			Max:       maxCardinality(field.Cardinality),
+		})
+		return
+	}
+
+	// entity reference: the last node in the path is the referenced entity
+	if len(array) < 3 {
+		return
+	}
+	class := field.LastClass()
+
+	var value ShapeExpr
+	if id, ok := g.classes[class]; ok {
+		value = ShapeRef(id)
+	} else {
+		value = typeShape(class, nil)
+	}
+	root.insert(array, &TripleConstraint{ValueExpr: value}, maxCardinality(field.Cardinality))
+}
+
+// literalConstraint returns the constraint for literal values of the given field
+func literalConstraint(field pathbuilder.Field) *NodeConstraint {
+	typ := field.XSDType()
+	if typ == rdf.XSDString {
+		// WissKI might store strings with or without language tags; so we can not restrict the datatype.
+		return &NodeConstraint{NodeKind: "literal"}
+	}
+	return &NodeConstraint{Datatype: typ}
+}
+
+// node is a node inside a prefix tree of paths starting at a bundle
+type node struct {
+	class    string
+	children []*edge             // edges to intermediate nodes, in insertion order
+	leaves   []*TripleConstraint // constraints ending at this node
+}
+
+type edge struct {
+	property string
+	node     *node
+	max      int // maximal cardinality of the edge
+	shared   bool
+}
+
+func newNode(class string) *node {
+	return &node{class: class}
+}
+
+// descend descends along all the steps of the given relative path array, creating new nodes as needed.
+// Returns the final node.
+func (n *node) descend(array []string, card int) *node {
+	current := n
+	for i := 1; i+1 < len(array); i += 2 {
+		current = current.child(array[i], array[i+1], card)
+	}
+	return current
+}
+
+// insert inserts a constraint for the last step of the relative path array into the tree.
+// The predicate of constraint is set to the last property.
+func (n *node) insert(array []string, constraint *TripleConstraint, card int) {
+	if len(array) < 3 {
+		return
+	}
+	parent := n.descend(array[:len(array)-2], card)
+	constraint.Predicate = array[len(array)-2]
+	constraint.Min = 0
+	constraint.Max = card
+	parent.leaves = append(parent.leaves, constraint)
+}
+
+// child returns the child node reached by property with the given class, creating it if needed.
+func (n *node) child(property, class string, card int) *node {
+	for _, e := range n.children {
+		if e.property == property && e.node.class == class {
+			e.shared = true
+			return e.node
+		}
+	}
+	e := &edge{property: property, node: newNode(class), max: card}
+	n.children = append(n.children, e)
+	return e.node
+}
+
+// shape turns this node into a shape
+func (n *node) shape() *Shape {
+	expressions := make([]TripleExpr, 0, len(n.children)+len(n.leaves))
+	for _, e := range n.children {
+		card := e.max
+		if e.shared {
+			card = Unbounded
+		}
+		expressions = append(expressions, &TripleConstraint{
+			Predicate: e.property,
+			ValueExpr: e.node.shape(),
+			Min:       0,
+			Max:       card,
+		})
+	}
+	for _, leaf := range n.leaves {
+		expressions = append(expressions, leaf)
+	}
+	return typeShape(n.class, expressions)
+}
+
+// typeShape returns a shape requiring the given class, and matching the given expressions
+func typeShape(class string, expressions []TripleExpr) *Shape {
+	if class != "" {
+		expressions = append([]TripleExpr{&TripleConstraint{
+			Predicate: rdf.Type,
+			ValueExpr: &NodeConstraint{Values: []string{class}},
+			Min:       1,
+			Max:       1,
+		}}, expressions...)
+	}
+
+	shape := &Shape{Extra: []string{rdf.Type}}
+	switch len(expressions) {
+	case 0:
+	case 1:
+		shape.Expression = expressions[0]
+	default:
+		shape.Expression = &EachOf{Expressions: expressions}
+	}
+	return shape
+}
+
+// maxCardinality turns a pathbuilder cardinality into a maximum cardinality
+func maxCardinality(cardinality int) int {
+	if cardinality <= 0 {
+		return Unbounded
+	}
+	return cardinality
+}
@@ -0,0 +1,70 @@
+package shex
+
+// cspell:words pathbuilder shex shexc
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/FAU-CDI/drincw/pathbuilder/pbxml"
+)
+
+// testPathbuilder is the pathbuilder shared by the tests of all pathbuilder packages
+var testPathbuilder = filepath.Join("..", "testdata", "pathbuilder.xml")
+
+func ExampleSchema_WriteShExC() {
+	pb, err := pbxml.Load(testPathbuilder)
+	if err != nil {
+		panic(err)
+	}
+
+	schema := New(pb, Options{Base: "http://example.com/shapes/"})
+	schema.WriteShExC(os.Stdout, map[string]string{
+		"ex":    "http://example.com/",
+		"shape": "http://example.com/shapes/",
+		"xsd":   "http://www.w3.org/2001/XMLSchema#",
+	})
+
+	// Output: PREFIX ex: <http://example.com/>
+	// PREFIX shape: <http://example.com/shapes/>
+	// PREFIX xsd: <http://www.w3.org/2001/XMLSchema#>
+	//
+	// shape:person EXTRA a {
+	//     a [ex:Person] ;
+	//     ex:hasName EXTRA a {
+	//         a [ex:Name] ;
+	//         ex:value LITERAL ? ;
+	//         ex:alias LITERAL *
+	//     } * ;
+	//     ex:born EXTRA a {
+	//         a [ex:Birth] ;
+	//         ex:at EXTRA a {
+	//             a [ex:Year] ;
+	//             ex:value xsd:integer ?
+	//         } ? ;
+	//         ex:in EXTRA a {
+	//             a [ex:Place] ;
+	//             ex:label LITERAL ?
+	//         } ?
+	//     } * ;
+	//     ex:knows @shape:person * ;
+	//     ex:participated @shape:event *
+	// }
+	//
+	// shape:event EXTRA a {
+	//     a [ex:Event] ;
+	//     ex:hasName EXTRA a {
+	//         a [ex:Name] ;
+	//         ex:value LITERAL ?
+	//     } ? ;
+	//     ex:hasParticipant @shape:participants {0,2}
+	// }
+	//
+	// shape:participants EXTRA a {
+	//     a [ex:Person] ;
+	//     ex:hasName EXTRA a {
+	//         a [ex:Name] ;
+	//         ex:value LITERAL ?
+	//     } ?
+	// }
+}
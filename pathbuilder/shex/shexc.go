@@ -0,0 +1,157 @@
+package shex
+
+// cspell:words shex shexc bnode nonliteral
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/internal/rdf"
+)
+
+// WriteShExC writes this schema in ShExC syntax into w.
+// Prefixes are used to compact iris in the output.
+func (schema Schema) WriteShExC(w io.Writer, prefixes map[string]string) error {
+	sw := shexcWriter{prefixes: rdf.Prefixes(prefixes)}
+
+	for _, name := range sw.prefixes.Names() {
+		fmt.Fprintf(&sw.builder, "PREFIX %s: %s\n", name, rdf.NewIRI(sw.prefixes[name]).String())
+	}
+
+	for _, decl := range schema.Shapes {
+		sw.builder.WriteString("\n")
+		sw.builder.WriteString(sw.iri(decl.ID))
+		sw.builder.WriteString(" ")
+		sw.shapeExpr(decl.ShapeExpr, "")
+		sw.builder.WriteString("\n")
+	}
+
+	_, err := io.WriteString(w, sw.builder.String())
+	return err
+}
+
+type shexcWriter struct {
+	prefixes rdf.Prefixes
+	builder  strings.Builder
+}
+
+func (sw *shexcWriter) iri(iri string) string {
+	if iri == rdf.Type {
+		return "a"
+	}
+	return sw.prefixes.FormatIRI(iri)
+}
+
+func (sw *shexcWriter) shapeExpr(expr ShapeExpr, indent string) {
+	switch expr := expr.(type) {
+	case ShapeRef:
+		sw.builder.WriteString("@")
+		sw.builder.WriteString(sw.prefixes.FormatIRI(string(expr)))
+	case *NodeConstraint:
+		sw.nodeConstraint(expr)
+	case *Shape:
+		sw.shape(expr, indent)
+	default:
+		sw.builder.WriteString(".")
+	}
+}
+
+func (sw *shexcWriter) nodeConstraint(nc *NodeConstraint) {
+	parts := make([]string, 0, 3)
+	if nc.NodeKind != "" {
+		parts = append(parts, strings.ToUpper(nc.NodeKind))
+	}
+	if nc.Datatype != "" {
+		parts = append(parts, sw.prefixes.FormatIRI(nc.Datatype))
+	}
+	if len(nc.Values) > 0 {
+		values := make([]string, len(nc.Values))
+		for i, value := range nc.Values {
+			values[i] = sw.prefixes.FormatIRI(value)
+		}
+		parts = append(parts, "["+strings.Join(values, " ")+"]")
+	}
+	if len(parts) == 0 {
+		parts = append(parts, ".")
+	}
+	sw.builder.WriteString(strings.Join(parts, " "))
+}
+
+func (sw *shexcWriter) shape(shape *Shape, indent string) {
+	if len(shape.Extra) > 0 {
+		sw.builder.WriteString("EXTRA")
+		for _, extra := range shape.Extra {
+			sw.builder.WriteString(" ")
+			sw.builder.WriteString(sw.iri(extra))
+		}
+		sw.builder.WriteString(" ")
+	}
+
+	sw.builder.WriteString("{")
+	var expressions []TripleExpr
+	switch expr := shape.Expression.(type) {
+	case nil:
+	case *EachOf:
+		expressions = expr.Expressions
+	default:
+		expressions = []TripleExpr{expr}
+	}
+
+	inner := indent + "    "
+	for i, expr := range expressions {
+		sw.builder.WriteString("\n")
+		sw.builder.WriteString(inner)
+		sw.tripleExpr(expr, inner)
+		if i < len(expressions)-1 {
+			sw.builder.WriteString(" ;")
+		}
+	}
+	if len(expressions) > 0 {
+		sw.builder.WriteString("\n")
+		sw.builder.WriteString(indent)
+	}
+	sw.builder.WriteString("}")
+}
+
+func (sw *shexcWriter) tripleExpr(expr TripleExpr, indent string) {
+	switch expr := expr.(type) {
+	case *TripleConstraint:
+		sw.builder.WriteString(sw.iri(expr.Predicate))
+		sw.builder.WriteString(" ")
+		sw.shapeExpr(expr.ValueExpr, indent)
+		if card := cardinality(expr.Min, expr.Max); card != "" {
+			sw.builder.WriteString(" ")
+			sw.builder.WriteString(card)
+		}
+	case *EachOf:
+		sw.builder.WriteString("(")
+		for i, expr := range expr.Expressions {
+			if i > 0 {
+				sw.builder.WriteString(" ; ")
+			}
+			sw.tripleExpr(expr, indent)
+		}
+		sw.builder.WriteString(")")
+	}
+}
+
+// cardinality formats a cardinality in ShExC syntax
+func cardinality(min, max int) string {
+	switch {
+	case min == 1 && max == 1:
+		return ""
+	case min == 0 && max == 1:
+		return "?"
+	case min == 0 && max == Unbounded:
+		return "*"
+	case min == 1 && max == Unbounded:
+		return "+"
+	case max == Unbounded:
+		return fmt.Sprintf("{%d,*}", min)
+	case min == max:
+		return fmt.Sprintf("{%d}", min)
+	default:
+		return fmt.Sprintf("{%d,%d}", min, max)
+	}
+}
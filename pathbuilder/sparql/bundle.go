@@ -0,0 +1,185 @@
+package sparql
+
+// cspell:words pathbuilder sparql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Layout describes the variables used by a bundle query.
+//
+// Each bundle and field is assigned a variable based on its position within the bundle tree.
+// The top-level bundle uses the variable "b", its fields "b_f0", "b_f1", ... and its child bundles "b_b0", "b_b1", ... .
+// Intermediate nodes of a path are named by appending "_n" and their index in the relative path array.
+type Layout struct {
+	Bundle   *pathbuilder.Bundle
+	Variable string // variable holding the uris of entities in the bundle (without '?')
+
+	Fields  []FieldVariable // enabled fields of the bundle, in order
+	Bundles []*Layout       // enabled child bundles, in order
+
+	pattern Pattern // pattern relative to the parent entity (or absolute for the top-level bundle)
+}
+
+// FieldVariable is a variable holding the values of a field
+type FieldVariable struct {
+	Field    pathbuilder.Field
+	Variable string // without '?'
+
+	pattern Pattern // pattern relative to the entity of the bundle
+}
+
+// BundleVariable is the variable holding the entities of the top-level bundle in a bundle query
+const BundleVariable = "b"
+
+// NewLayout returns the layout for queries of the given bundle.
+func NewLayout(bundle *pathbuilder.Bundle) *Layout {
+	return newLayout(bundle, BundleVariable, layoutPattern(bundle.PathArray, "", "", BundleVariable))
+}
+
+func newLayout(bundle *pathbuilder.Bundle, name string, pattern Pattern) *Layout {
+	layout := &Layout{
+		Bundle:   bundle,
+		Variable: name,
+		pattern:  pattern,
+	}
+
+	for _, field := range bundle.Fields() {
+		if !field.Enabled {
+			continue
+		}
+
+		variable := name + "_f" + strconv.Itoa(len(layout.Fields))
+		layout.Fields = append(layout.Fields, FieldVariable{
+			Field:    field,
+			Variable: variable,
+			pattern:  layoutPattern(field.RelativePathArray(bundle), fieldDatatype(field.Path), "?"+name, variable),
+		})
+	}
+
+	for _, child := range bundle.Bundles() {
+		if !child.Enabled {
+			continue
+		}
+		variable := name + "_b" + strconv.Itoa(len(layout.Bundles))
+		pattern := layoutPattern(child.RelativePathArray(bundle), "", "?"+name, variable)
+		layout.Bundles = append(layout.Bundles, newLayout(child, variable, pattern))
+	}
+
+	return layout
+}
+
+// fieldDatatype returns the datatype property to use for the given field
+func fieldDatatype(path pathbuilder.Path) string {
+	if path.IsGroup || path.IsEntityReference() {
+		return ""
+	}
+	return path.Datatype()
+}
+
+// layoutPattern creates a pattern for the given (relative) path array.
+//
+// If start is non-empty, it is used as the node of the first class, and no type triple is generated for it.
+// The value of the pattern (the datatype property value or the last node) is called name.
+func layoutPattern(array []string, datatype string, start string, name string) (p Pattern) {
+	last := len(array) - 1
+	if last%2 == 1 {
+		last--
+	}
+
+	node := func(i int) string {
+		switch {
+		case i == 0 && start != "":
+			return start
+		case i == last && datatype == "":
+			return "?" + name
+		}
+		return fmt.Sprintf("?%s_n%d", name, i)
+	}
+
+	var current string
+	for i, uri := range array {
+		if i%2 == 0 {
+			current = node(i)
+			p.Nodes = append(p.Nodes, current)
+			if i != 0 || start == "" {
+				p.Triples = append(p.Triples, fmt.Sprintf("%s a %s", current, IRI(uri)))
+			}
+			continue
+		}
+
+		p.Triples = append(p.Triples, fmt.Sprintf("%s %s %s", current, IRI(uri), node(i+1)))
+	}
+
+	if datatype != "" && current != "" {
+		p.Value = "?" + name
+		p.Triples = append(p.Triples, fmt.Sprintf("%s %s %s", current, IRI(datatype), p.Value))
+	}
+
+	// the value variable must always be bound, even if the path does not introduce any new nodes
+	if target := p.Target(); target != "" && target != "?"+name {
+		p.Triples = append(p.Triples, fmt.Sprintf("BIND(%s AS ?%s)", target, name))
+	}
+
+	return p
+}
+
+// NewBundleQuery generates a SELECT query for all enabled fields and child bundles of the given bundle.
+// Each field and child bundle is matched within an OPTIONAL block; variables are named according to NewLayout.
+//
+// Limit and Offset apply to the number of entities, not the number of solutions.
+// Regex, Flags and Languages are ignored.
+func NewBundleQuery(bundle *pathbuilder.Bundle, opts Options) string {
+	layout := NewLayout(bundle)
+
+	var builder strings.Builder
+	indent := opts.writeHead(&builder)
+
+	if opts.Entity != "" {
+		fmt.Fprintf(&builder, "%sVALUES ?%s { %s }\n", indent, layout.Variable, IRI(opts.Entity))
+	}
+
+	if opts.Limit > 0 || opts.Offset > 0 {
+		// select the entities in a subquery, so that limit and offset apply to entities
+		fmt.Fprintf(&builder, "%s{\n", indent)
+		fmt.Fprintf(&builder, "%s    SELECT DISTINCT ?%s WHERE {\n", indent, layout.Variable)
+		layout.pattern.Write(&builder, indent+"        ")
+		fmt.Fprintf(&builder, "%s    }\n", indent)
+		fmt.Fprintf(&builder, "%s    ORDER BY ?%s\n", indent, layout.Variable)
+		if opts.Limit > 0 {
+			fmt.Fprintf(&builder, "%s    LIMIT %d\n", indent, opts.Limit)
+		}
+		if opts.Offset > 0 {
+			fmt.Fprintf(&builder, "%s    OFFSET %d\n", indent, opts.Offset)
+		}
+		fmt.Fprintf(&builder, "%s}\n", indent)
+	} else {
+		layout.pattern.Write(&builder, indent)
+	}
+	layout.writeOptionals(&builder, indent)
+
+	tail := opts
+	tail.Limit, tail.Offset = 0, 0
+	tail.writeTail(&builder, indent, "?"+layout.Variable)
+
+	return builder.String()
+}
+
+// writeOptionals writes OPTIONAL blocks for the fields and child bundles of this layout
+func (layout *Layout) writeOptionals(builder *strings.Builder, indent string) {
+	for _, field := range layout.Fields {
+		builder.WriteString(indent + "OPTIONAL {\n")
+		field.pattern.Write(builder, indent+"    ")
+		builder.WriteString(indent + "}\n")
+	}
+	for _, child := range layout.Bundles {
+		builder.WriteString(indent + "OPTIONAL {\n")
+		child.pattern.Write(builder, indent+"    ")
+		child.writeOptionals(builder, indent+"    ")
+		builder.WriteString(indent + "}\n")
+	}
+}
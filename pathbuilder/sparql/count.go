@@ -0,0 +1,34 @@
+package sparql
+
+// cspell:words pathbuilder sparql
+
+import (
+	"strings"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// CountVariable is the name of the variable holding the result of count queries
+const CountVariable = "count"
+
+// CountEntities returns a query that counts the number of distinct entities of the given bundle.
+func CountEntities(bundle *pathbuilder.Bundle) string {
+	pattern := NewPattern(bundle.Path, "")
+	return countQuery(pattern.Node(bundle), pattern)
+}
+
+// CountField returns a query that counts the number of distinct entities of the given bundle that have at least one value for the given field.
+func CountField(bundle *pathbuilder.Bundle, field pathbuilder.Field) string {
+	pattern := NewPattern(field.Path, "")
+	return countQuery(pattern.Node(bundle), pattern)
+}
+
+func countQuery(variable string, pattern Pattern) string {
+	var builder strings.Builder
+	builder.WriteString("SELECT (COUNT(DISTINCT ")
+	builder.WriteString(variable)
+	builder.WriteString(") AS ?" + CountVariable + ") WHERE {\n")
+	pattern.Write(&builder, "    ")
+	builder.WriteString("}")
+	return builder.String()
+}
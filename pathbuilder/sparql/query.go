@@ -0,0 +1,199 @@
+package sparql
+
+// cspell:words pathbuilder sparql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// GraphMode determines which graphs a query is evaluated against
+type GraphMode int
+
+const (
+	// GraphVariable wraps the pattern in 'GRAPH ?g { ... }', matching any named graph
+	GraphVariable GraphMode = iota
+	// GraphDefault queries the default graph only
+	GraphDefault
+	// GraphNamed wraps the pattern in 'GRAPH <iri> { ... }', matching only the named graph Options.GraphIRI
+	GraphNamed
+	// GraphFrom adds a 'FROM <iri>' clause, making Options.GraphIRI the default graph of the query
+	GraphFrom
+)
+
+// ParseGraphMode parses a graph mode from its name, one of "variable", "default", "named" or "from".
+func ParseGraphMode(name string) (GraphMode, error) {
+	switch name {
+	case "variable":
+		return GraphVariable, nil
+	case "default":
+		return GraphDefault, nil
+	case "named":
+		return GraphNamed, nil
+	case "from":
+		return GraphFrom, nil
+	}
+	return GraphVariable, fmt.Errorf("unknown graph mode %q", name)
+}
+
+// GraphVariableName is the name of the variable used for GraphVariable
+const GraphVariableName = "g"
+
+// Options determine the shape of generated queries
+type Options struct {
+	Graph    GraphMode
+	GraphIRI string // iri used by GraphNamed and GraphFrom
+
+	Distinct bool // use 'SELECT DISTINCT'
+	Limit    int  // if positive, add a 'LIMIT' clause
+	Offset   int  // if positive, add an 'OFFSET' clause
+
+	Entity    string   // if non-empty, only match the entity with the given uri
+	Regex     string   // if non-empty, only match values whose string form matches the given regular expression
+	Flags     string   // flags for Regex, e.g. "i" for case-insensitive matching
+	Languages []string // if non-empty, only match literal values with one of the given language ranges
+}
+
+// Variable describes the part of a path a variable is bound to
+type Variable struct {
+	Name     string `json:"name"`               // name of the variable (without '?')
+	Index    int    `json:"index"`              // index in the path array; the length of the path array for the datatype property value
+	Class    string `json:"class,omitempty"`    // class of the node (if any)
+	Property string `json:"property,omitempty"` // property leading to the variable (if any)
+}
+
+// Query is a generated query along with information about its variables
+type Query struct {
+	SPARQL    string     `json:"query"`
+	Variables []Variable `json:"variables"` // variables in the order they occur in the path
+}
+
+// NewQuery generates a SELECT query for the values of the given path
+func NewQuery(path pathbuilder.Path, opts Options) Query {
+	pattern := NewPattern(path, "")
+
+	var builder strings.Builder
+	indent := opts.writeHead(&builder)
+
+	if opts.Entity != "" && len(pattern.Nodes) > 0 {
+		fmt.Fprintf(&builder, "%sVALUES %s { %s }\n", indent, pattern.Nodes[0], IRI(opts.Entity))
+	}
+	pattern.Write(&builder, indent)
+	for _, filter := range opts.filters(pattern) {
+		fmt.Fprintf(&builder, "%sFILTER(%s)\n", indent, filter)
+	}
+
+	opts.writeTail(&builder, indent, "")
+
+	return Query{
+		SPARQL:    builder.String(),
+		Variables: variables(path, pattern, opts),
+	}
+}
+
+// writeHead writes the beginning of a SELECT query, up to and including the opening of the graph block (if any).
+// It returns the indent to use for the body of the query.
+func (opts Options) writeHead(builder *strings.Builder) (indent string) {
+	builder.WriteString("SELECT ")
+	if opts.Distinct {
+		builder.WriteString("DISTINCT ")
+	}
+	builder.WriteString("*")
+	if opts.Graph == GraphFrom && opts.GraphIRI != "" {
+		builder.WriteString("\nFROM ")
+		builder.WriteString(IRI(opts.GraphIRI))
+		builder.WriteString("\nWHERE {\n")
+	} else {
+		builder.WriteString(" WHERE {\n")
+	}
+
+	switch {
+	case opts.Graph == GraphVariable:
+		builder.WriteString("    GRAPH ?" + GraphVariableName + " {\n")
+		return "        "
+	case opts.Graph == GraphNamed && opts.GraphIRI != "":
+		builder.WriteString("    GRAPH " + IRI(opts.GraphIRI) + " {\n")
+		return "        "
+	}
+	return "    "
+}
+
+// writeTail closes the blocks opened by writeHead and writes solution modifiers.
+// If orderBy is non-empty, an 'ORDER BY' clause is added.
+func (opts Options) writeTail(builder *strings.Builder, indent string, orderBy string) {
+	if indent != "    " {
+		builder.WriteString("    }\n")
+	}
+	builder.WriteString("}")
+
+	if orderBy != "" {
+		builder.WriteString("\nORDER BY ")
+		builder.WriteString(orderBy)
+	}
+	if opts.Limit > 0 {
+		builder.WriteString("\nLIMIT ")
+		builder.WriteString(strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		builder.WriteString("\nOFFSET ")
+		builder.WriteString(strconv.Itoa(opts.Offset))
+	}
+}
+
+// filters returns the filter expressions for the given pattern
+func (opts Options) filters(pattern Pattern) (filters []string) {
+	target := pattern.Target()
+	if target == "" {
+		return nil
+	}
+
+	if opts.Regex != "" {
+		if opts.Flags != "" {
+			filters = append(filters, fmt.Sprintf("regex(str(%s), %s, %s)", target, Literal(opts.Regex), Literal(opts.Flags)))
+		} else {
+			filters = append(filters, fmt.Sprintf("regex(str(%s), %s)", target, Literal(opts.Regex)))
+		}
+	}
+
+	if len(opts.Languages) > 0 && pattern.Value != "" {
+		conditions := make([]string, len(opts.Languages))
+		for i, lang := range opts.Languages {
+			conditions[i] = fmt.Sprintf("langMatches(lang(%s), %s)", pattern.Value, Literal(lang))
+		}
+		filters = append(filters, strings.Join(conditions, " || "))
+	}
+
+	return filters
+}
+
+// variables returns information about the variables used by a query
+func variables(path pathbuilder.Path, pattern Pattern, opts Options) []Variable {
+	variables := make([]Variable, 0, len(pattern.Nodes)+2)
+	if opts.Graph == GraphVariable {
+		variables = append(variables, Variable{Name: GraphVariableName, Index: -1})
+	}
+
+	for i, node := range pattern.Nodes {
+		v := Variable{
+			Name:  strings.TrimPrefix(node, "?"),
+			Index: 2 * i,
+			Class: path.PathArray[2*i],
+		}
+		if i > 0 {
+			v.Property = path.PathArray[2*i-1]
+		}
+		variables = append(variables, v)
+	}
+
+	if pattern.Value != "" {
+		variables = append(variables, Variable{
+			Name:     strings.TrimPrefix(pattern.Value, "?"),
+			Index:    len(path.PathArray),
+			Property: path.Datatype(),
+		})
+	}
+	return variables
+}
@@ -0,0 +1,44 @@
+package sparql
+
+import (
+	"fmt"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+func ExampleNewQuery() {
+	path := pathbuilder.Path{
+		ID:               "name",
+		PathArray:        []string{"http://example.com/Person", "http://example.com/named", "http://example.com/Name"},
+		DatatypeProperty: "http://example.com/value",
+	}
+
+	query := NewQuery(path, Options{
+		Graph:     GraphNamed,
+		GraphIRI:  "http://example.com/data",
+		Distinct:  true,
+		Limit:     10,
+		Regex:     "smith",
+		Flags:     "i",
+		Languages: []string{"en"},
+	})
+	fmt.Println(query.SPARQL)
+	for _, v := range query.Variables {
+		fmt.Printf("%s %d class=%q property=%q\n", v.Name, v.Index, v.Class, v.Property)
+	}
+
+	// Output: SELECT DISTINCT * WHERE {
+	//     GRAPH <http://example.com/data> {
+	//         ?v0 a <http://example.com/Person> .
+	//         ?v0 <http://example.com/named> ?v2 .
+	//         ?v2 a <http://example.com/Name> .
+	//         ?v2 <http://example.com/value> ?v3 .
+	//         FILTER(regex(str(?v3), "smith", "i"))
+	//         FILTER(langMatches(lang(?v3), "en"))
+	//     }
+	// }
+	// LIMIT 10
+	// v0 0 class="http://example.com/Person" property=""
+	// v2 2 class="http://example.com/Name" property="http://example.com/named"
+	// v3 3 class="" property="http://example.com/value"
+}
@@ -0,0 +1,131 @@
+// Package sparql generates sparql queries for paths of a pathbuilder
+package sparql
+
+// cspell:words pathbuilder sparql
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/FAU-CDI/drincw/pathbuilder"
+)
+
+// Pattern represents a basic graph pattern matching a single path.
+//
+// Variables are named by the index of the corresponding element in the path array.
+// For example, with the default variable prefix the node matching the first class in the path array is called "?v0", the node matching the second class "?v2".
+// The value of the datatype property is named after the length of the path array.
+type Pattern struct {
+	Triples []string // triple patterns, without the terminating " ."
+
+	Nodes []string // variable names for each class in the path array
+	Value string   // variable name for the datatype property value, or empty if there is none
+}
+
+// DefaultPrefix is the default prefix for variable names
+const DefaultPrefix = "v"
+
+// NewPattern creates a new pattern for the given path.
+// Prefix is used as a prefix for variable names; when empty, DefaultPrefix is used.
+func NewPattern(path pathbuilder.Path, prefix string) (p Pattern) {
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	p.Nodes = make([]string, 0, len(path.PathArray)/2+1)
+
+	var current string
+	for i, uri := range path.PathArray {
+		if i%2 == 0 {
+			current = fmt.Sprintf("?%s%d", prefix, i)
+			p.Nodes = append(p.Nodes, current)
+			p.Triples = append(p.Triples, fmt.Sprintf("%s a %s", current, IRI(uri)))
+			continue
+		}
+
+		next := fmt.Sprintf("?%s%d", prefix, i+1)
+		p.Triples = append(p.Triples, fmt.Sprintf("%s %s %s", current, IRI(uri), next))
+	}
+
+	if datatype := path.Datatype(); !path.IsGroup && datatype != "" && current != "" {
+		p.Value = fmt.Sprintf("?%s%d", prefix, len(path.PathArray))
+		p.Triples = append(p.Triples, fmt.Sprintf("%s %s %s", current, IRI(datatype), p.Value))
+	}
+
+	return p
+}
+
+// Node returns the variable for the node corresponding to the last class of the given bundle.
+// If bundle is nil or does not have any classes, returns the first node.
+func (p Pattern) Node(bundle *pathbuilder.Bundle) string {
+	if len(p.Nodes) == 0 {
+		return ""
+	}
+
+	var index int
+	if bundle != nil && len(bundle.PathArray) > 0 {
+		index = (len(bundle.PathArray) - 1) / 2
+	}
+	if index >= len(p.Nodes) {
+		index = len(p.Nodes) - 1
+	}
+	return p.Nodes[index]
+}
+
+// Target returns the variable holding the values of the path.
+// This is the datatype property value if it exists, and the last node otherwise.
+func (p Pattern) Target() string {
+	if p.Value != "" {
+		return p.Value
+	}
+	if len(p.Nodes) == 0 {
+		return ""
+	}
+	return p.Nodes[len(p.Nodes)-1]
+}
+
+// Write writes the triple patterns into builder, each on a separate line prefixed by indent.
+func (p Pattern) Write(builder *strings.Builder, indent string) {
+	for _, triple := range p.Triples {
+		builder.WriteString(indent)
+		builder.WriteString(triple)
+		builder.WriteString(" .\n")
+	}
+}
+
+// String returns the triple patterns, one per line
+func (p Pattern) String() string {
+	var builder strings.Builder
+	p.Write(&builder, "")
+	return builder.String()
+}
+
+// IRI formats an iri for use within a sparql query
+func IRI(iri string) string {
+	return "<" + iriEscaper.Replace(iri) + ">"
+}
+
+var iriEscaper = strings.NewReplacer(
+	">", "%3E",
+	"<", "%3C",
+	" ", "%20",
+	"\"", "%22",
+	"{", "%7B",
+	"}", "%7D",
+	"|", "%7C",
+	"\\", "%5C",
+	"^", "%5E",
+	"`", "%60",
+)
+
+// Literal formats a string literal for use within a sparql query
+func Literal(value string) string {
+	return `"` + literalEscaper.Replace(value) + `"`
+}
+
+var literalEscaper = strings.NewReplacer(
+	"\\", "\\\\",
+	"\"", "\\\"",
+	"\n", "\\n",
+	"\r", "\\r",
+)